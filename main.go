@@ -1,69 +1,415 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/crosleyzack/bubbles/tree"
 	"github.com/crosleyzack/bubbles/utils"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
-var (
-	styleDoc = lipgloss.NewStyle().Padding(1)
-)
-
 func main() {
 	if err := RootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-var RootCmd = &cobra.Command{}
+// RootCmd leaves printing errors to main, so that each is printed once
+var RootCmd = &cobra.Command{SilenceErrors: true}
 
 func init() {
 	RootCmd.AddCommand(GetRunCmd())
+	RootCmd.AddCommand(GetQueryCmd())
+	RootCmd.AddCommand(GetDiffCmd())
+	RootCmd.AddCommand(GetWatchCmd())
+	RootCmd.AddCommand(GetPickCmd())
+}
+
+// readStdin returns true if input should be read from stdin, either because the
+// path is - or because no path was given and stdin is not a terminal.
+func readStdin(path string) bool {
+	return path == "-" || (path == "" && !term.IsTerminal(int(os.Stdin.Fd())))
+}
+
+// inputFormat returns the format named by format, falling back to the extension
+// of path. It is empty if the format must be detected from the content.
+func inputFormat(path string, format string) (utils.Format, error) {
+	f, err := utils.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if f == "" && !readStdin(path) {
+		f = utils.FormatFromPath(path)
+	}
+	return f, nil
+}
+
+// openInput opens the file at path, or stdin if readStdin is true.
+func openInput(path string) (io.ReadCloser, error) {
+	switch {
+	case readStdin(path):
+		return io.NopCloser(os.Stdin), nil
+	case path == "":
+		return nil, fmt.Errorf("no input, pass --file or pipe data to stdin")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	return f, nil
+}
+
+// readDocument reads and parses the file at path, or stdin if readStdin is true.
+// If the format is not given it is chosen from the file extension, falling back
+// to detecting it from the content.
+func readDocument(path string, format string) (*utils.Document, error) {
+	f, err := inputFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+	r, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return loadDocument(r, f)
+}
+
+// loadDocument reads and parses all of r in the given format, detecting it from
+// the content if it is empty.
+func loadDocument(r io.Reader, f utils.Format) (*utils.Document, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	if f == "" {
+		f = utils.DetectFormat(content)
+	}
+	doc, err := utils.Load(content, f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing file as %s: %w", f, err)
+	}
+	return doc, nil
 }
 
 func GetRunCmd() *cobra.Command {
 	var file string
+	var format string
+	var pathFormat string
+	var guides string
+	var badges bool
+	var label string
+	var follow bool
+	var watch bool
 	cmd := &cobra.Command{
-		Use:     "run",
-		Example: "run --file data.json",
-		Args:    cobra.ExactArgs(0),
+		Use:          "run",
+		Example:      "run --file data.json\nrun < data.json\nrun --file app.log.ndjson --follow --label timestamp\nrun --file config.yaml --watch",
+		Args:         cobra.ExactArgs(0),
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			w, h, err := term.GetSize(int(os.Stdout.Fd()))
+			f, err := inputFormat(file, format)
 			if err != nil {
-				w = 80
-				h = 24
+				return err
+			}
+			if follow && f != utils.FormatNDJSON {
+				return fmt.Errorf("--follow requires NDJSON input, pass --format ndjson")
+			}
+			if watch && (follow || readStdin(file)) {
+				return fmt.Errorf("--watch requires a file and cannot be used with --follow")
 			}
-			top, right, bottom, left := styleDoc.GetPadding()
-			w = w - left - right
-			h = h - top - bottom
-			content, err := os.ReadFile(file)
+			if watch && label != "" {
+				return fmt.Errorf("--label cannot be used with --watch")
+			}
+			var treeModel *tree.Model
+			var model tea.Model
+			switch {
+			case watch:
+				// watch before reading, so that changes made while it is read are not missed
+				watcher, err := utils.NewFileWatcher(file, f)
+				if err != nil {
+					return err
+				}
+				doc, err := readDocument(file, format)
+				if err != nil {
+					return err
+				}
+				treeModel = doc.Treeify()
+				model = utils.NewWatchModel(doc, treeModel, watcher)
+			default:
+				r, err := openInput(file)
+				if err != nil {
+					return err
+				}
+				defer r.Close()
+				// sniff for NDJSON rather than reading everything, so that it can still be streamed
+				input := bufio.NewReaderSize(r, 1<<20)
+				if f == "" && utils.SniffNDJSON(input) {
+					f = utils.FormatNDJSON
+				}
+				if f == utils.FormatNDJSON {
+					// parse lines as they are read so the first are shown straight away
+					doc := &utils.Document{}
+					treeModel = doc.Treeify()
+					model = utils.NewStreamModel(doc, treeModel, utils.NewNDJSONStream(input, label, follow))
+					break
+				}
+				if label != "" {
+					return fmt.Errorf("--label requires NDJSON input")
+				}
+				doc, err := loadDocument(input, f)
+				if err != nil {
+					return err
+				}
+				treeModel = doc.Treeify()
+				model = utils.NewModel(treeModel)
+			}
+			treeModel.PathFormat, err = tree.ParsePathFormat(pathFormat)
+			if err != nil {
+				return err
+			}
+			treeModel.Styles.Guides, err = tree.ParseGuides(guides)
 			if err != nil {
-				log.Fatal("Error when opening file: ", err)
+				return err
+			}
+			treeModel.ShowBadges = badges
+			opts := []tea.ProgramOption{tea.WithMouseCellMotion()}
+			if readStdin(file) {
+				// stdin is the piped input, so read key presses from the terminal
+				opts = append(opts, tea.WithInputTTY())
 			}
-			// Now let's unmarshall the data into `payload`
-			var result utils.JsonBlob
-			err = json.Unmarshal(content, &result)
+			_, err = tea.NewProgram(model, opts...).Run()
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "JSON, NDJSON or YAML file to display, or - for stdin")
+	cmd.Flags().StringVar(&format, "format", "", "input format, json, ndjson or yaml, detected by default")
+	cmd.Flags().StringVar(&pathFormat, "path-format", "jsonpath", "syntax for copied paths: jsonpath, pointer, jq or template")
+	cmd.Flags().StringVar(&guides, "guides", "unicode", "lines drawn between nodes: unicode, rounded, ascii or none")
+	cmd.Flags().BoolVar(&badges, "badges", true, "show the size of arrays and objects and quote strings")
+	cmd.Flags().StringVar(&label, "label", "", "field used to label NDJSON lines, line numbers by default")
+	cmd.Flags().BoolVar(&follow, "follow", false, "keep reading lines appended to an NDJSON file")
+	cmd.Flags().BoolVar(&watch, "watch", false, "reload the file when it changes")
+	return cmd
+}
+
+func GetQueryCmd() *cobra.Command {
+	var file string
+	var format string
+	var raw bool
+	cmd := &cobra.Command{
+		Use:          "query <expression>",
+		Short:        "Print the results of a jq style query",
+		Example:      "query --file data.json '.. | select(.country_code? == \"US\") | .name'",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := readDocument(file, format)
 			if err != nil {
-				log.Fatal("Error during Unmarshal(): ", err)
+				return err
 			}
-			model := result.Treeify()
-			model.SetHeight(h)
-			model.SetWidth(w)
-			program := tea.NewProgram(utils.NewModel(model))
-			_, err = program.Run()
+			values, err := doc.Query(args[0])
 			if err != nil {
-				log.Fatal("Error during program start: ", err)
+				return err
+			}
+			for _, value := range values {
+				if s, ok := value.(string); ok && raw {
+					fmt.Fprintln(cmd.OutOrStdout(), s)
+					continue
+				}
+				out, err := json.MarshalIndent(value, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "JSON, NDJSON or YAML file to query, or - for stdin")
+	cmd.Flags().StringVar(&format, "format", "", "input format, json, ndjson or yaml, detected by default")
+	cmd.Flags().BoolVarP(&raw, "raw", "r", false, "print strings without quotes")
+	return cmd
+}
+
+func GetDiffCmd() *cobra.Command {
+	var format string
+	var key string
+	var changesOnly bool
+	var pathFormat string
+	var guides string
+	cmd := &cobra.Command{
+		Use:          "diff <old> <new>",
+		Short:        "Show the differences between two documents",
+		Example:      "diff staging.json prod.json\ndiff --key id --only-changes old.yaml new.yaml\ncurl -s https://example.com/api | diff saved.json -",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if args[0] == "-" && args[1] == "-" {
+				return fmt.Errorf("only one document can be read from stdin")
+			}
+			oldDoc, err := readDocument(args[0], format)
+			if err != nil {
+				return err
+			}
+			newDoc, err := readDocument(args[1], format)
+			if err != nil {
+				return err
+			}
+			treeModel := tree.New(utils.DiffDocuments(oldDoc, newDoc, key), 1, 1)
+			treeModel.SetShowChanges(true)
+			treeModel.SetChangesOnly(changesOnly)
+			treeModel.PathFormat, err = tree.ParsePathFormat(pathFormat)
+			if err != nil {
+				return err
+			}
+			treeModel.Styles.Guides, err = tree.ParseGuides(guides)
+			if err != nil {
+				return err
+			}
+			opts := []tea.ProgramOption{tea.WithMouseCellMotion()}
+			if args[0] == "-" || args[1] == "-" {
+				opts = append(opts, tea.WithInputTTY())
+			}
+			_, err = tea.NewProgram(utils.NewDiffModel(treeModel), opts...).Run()
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "input format, json, ndjson or yaml, detected by default")
+	cmd.Flags().StringVar(&key, "key", "", "field used to match objects in arrays, such as id")
+	cmd.Flags().BoolVar(&changesOnly, "only-changes", false, "hide unchanged nodes, toggled with o")
+	cmd.Flags().StringVar(&pathFormat, "path-format", "jsonpath", "syntax for copied paths: jsonpath, pointer, jq or template")
+	cmd.Flags().StringVar(&guides, "guides", "unicode", "lines drawn between nodes: unicode, rounded, ascii or none")
+	return cmd
+}
+
+func GetWatchCmd() *cobra.Command {
+	var interval time.Duration
+	var format string
+	var pathFormat string
+	var guides string
+	cmd := &cobra.Command{
+		Use:   "watch [flags] -- <command...>",
+		Short: "Run a command on an interval and show its output",
+		Long: "Run a command on an interval and show its output. A single argument is run with sh, so may\n" +
+			"use pipes and quoting, while several are run directly as the program and its arguments.",
+		Example:      "watch -- kubectl get pods -o json\nwatch --interval 5s -- curl -s https://example.com/api/status\nwatch -- 'curl -s https://example.com/api | jq .items'",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			f, err := utils.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+			// the command is shown once it has run for the first time
+			doc := &utils.Document{}
+			treeModel := doc.Treeify()
+			watcher := utils.NewCommandWatcher(args, interval, f)
+			treeModel.PathFormat, err = tree.ParsePathFormat(pathFormat)
+			if err != nil {
+				return err
+			}
+			treeModel.Styles.Guides, err = tree.ParseGuides(guides)
+			if err != nil {
+				return err
+			}
+			model := utils.NewCommandModel(doc, treeModel, watcher)
+			_, err = tea.NewProgram(model, tea.WithMouseCellMotion()).Run()
+			return err
+		},
+	}
+	cmd.Flags().DurationVarP(&interval, "interval", "n", 2*time.Second, "time between runs of the command")
+	cmd.Flags().StringVar(&format, "format", "", "output format, json, ndjson or yaml, detected by default")
+	cmd.Flags().StringVar(&pathFormat, "path-format", "jsonpath", "syntax for copied paths: jsonpath, pointer, jq or template")
+	cmd.Flags().StringVar(&guides, "guides", "unicode", "lines drawn between nodes: unicode, rounded, ascii or none")
+	return cmd
+}
+
+// exitCancelled is the exit code of pick when it quits without a choice, as used by fzf
+const exitCancelled = 130
+
+func GetPickCmd() *cobra.Command {
+	var file string
+	var format string
+	var output string
+	var pathFormat string
+	var multi bool
+	cmd := &cobra.Command{
+		Use:   "pick",
+		Short: "Choose nodes and print their paths or values",
+		Long: "Choose nodes and print their paths or values, one per line. Enter chooses the selected node,\n" +
+			"or with --multi the nodes picked with space. The tree is drawn on the terminal, so the output\n" +
+			"may be piped or captured. It exits with status 0 once nodes are chosen and 130 if cancelled.",
+		Example:      "pick --file data.json\ncurl -s https://example.com/api | pick --output value\njq -r \"$(pick --file data.json --path-format jq)\" data.json",
+		Args:         cobra.ExactArgs(0),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "path" && output != "value" {
+				return fmt.Errorf("unknown output %q, expected path or value", output)
+			}
+			paths, err := tree.ParsePathFormat(pathFormat)
+			if err != nil {
+				return err
+			}
+			// stdout is kept for the choice, so draw on and read keys from the terminal
+			tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+			if err != nil {
+				return fmt.Errorf("opening terminal: %w", err)
+			}
+			defer tty.Close()
+			lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(tty))
+			doc, err := readDocument(file, format)
+			if err != nil {
+				return err
+			}
+			treeModel := doc.Treeify()
+			treeModel.PathFormat = paths
+			treeModel.Output = tty
+			treeModel.SetMultiPick(multi)
+			final, err := tea.NewProgram(utils.NewPickModel(treeModel),
+				tea.WithInput(tty), tea.WithOutput(tty), tea.WithMouseCellMotion()).Run()
+			if err != nil {
+				return err
+			}
+			nodes, ok := utils.Picked(final)
+			if !ok {
+				tty.Close()
+				os.Exit(exitCancelled)
+			}
+			for _, node := range nodes {
+				if output == "path" {
+					fmt.Fprintln(cmd.OutOrStdout(), node.FormatPath(treeModel.PathFormat))
+					continue
+				}
+				// values are written on one line each, with strings unquoted
+				if s, ok := node.Data.(string); ok {
+					fmt.Fprintln(cmd.OutOrStdout(), s)
+					continue
+				}
+				out, err := json.Marshal(node.Data)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(out))
 			}
 			return nil
 		},
 	}
-	cmd.Flags().StringVar(&file, "file", "", "JSON file to display")
+	cmd.Flags().StringVar(&file, "file", "", "JSON, NDJSON or YAML file to pick from, or - for stdin")
+	cmd.Flags().StringVar(&format, "format", "", "input format, json, ndjson or yaml, detected by default")
+	cmd.Flags().StringVarP(&output, "output", "o", "path", "what to print for each node: path or value")
+	cmd.Flags().StringVar(&pathFormat, "path-format", "jsonpath", "syntax for printed paths: jsonpath, pointer, jq or template")
+	cmd.Flags().BoolVarP(&multi, "multi", "m", false, "pick several nodes with space")
 	return cmd
 }
@@ -0,0 +1,40 @@
+package tree
+
+// AppendNodes adds nodes after the existing top level nodes, keeping the cursor
+// on the selected node. Any sort, filter or search is applied to the new nodes.
+func (m *Model) AppendNodes(nodes ...*Node) {
+	if len(nodes) == 0 {
+		return
+	}
+	linkParents(nodes, nil)
+	// copy rather than append in place, as the caller may share the backing array
+	m.nodes = append(m.nodes[:len(m.nodes):len(m.nodes)], nodes...)
+
+	if m.sort.original != nil {
+		m.sort.original[nil] = append(m.sort.original[nil], nodes...)
+		walkNodes(nodes, nil, func(node *Node, _ []*Node) {
+			if len(node.Children) > 0 {
+				m.sort.original[node] = append([]*Node(nil), node.Children...)
+			}
+		})
+		if m.sort.mode != SortDocument {
+			m.SetSortMode(m.sort.mode)
+		}
+	}
+	if m.Filtered() {
+		walkNodes(nodes, nil, func(node *Node, _ []*Node) {
+			m.filter.expanded[node] = node.Expand
+		})
+		if match, err := m.filterMatch(); err == nil {
+			m.prune(nodes, match)
+		}
+	}
+	if m.rows.valid && m.sort.mode == SortDocument && m.zoom.root == nil {
+		m.rows.rows = m.appendRows(m.rows.rows, nodes, 0)
+	} else {
+		m.invalidateRows()
+	}
+
+	m.selectNode(m.currentNode)
+	m.findMatches()
+}
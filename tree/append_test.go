@@ -0,0 +1,28 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendNodes(t *testing.T) {
+	m := New(nil, 80, 24)
+	m.AppendNodes(testNodes()...)
+	assert.Equal(t, "ip", m.currentNode.Value)
+	assert.Equal(t, 3, m.NumberOfNodes())
+
+	m.SetCursor(2)
+	m.SetSortMode(SortKey)
+	m.SetFilter("a")
+	m.AppendNodes(&Node{Value: "aardvark"}, &Node{Value: "zebra"}, &Node{Value: "alpha", Children: []*Node{{Value: "b"}}})
+	assert.Equal(t, []string{"aardvark", "alpha", "asn", "ip", "location", "zebra"}, sortedValues(m.nodes))
+	assert.True(t, m.shown(m.nodes[0]))
+	assert.False(t, m.shown(m.nodes[3]))
+	assert.Equal(t, "asn", m.currentNode.Value)
+
+	m.ClearFilter()
+	m.SetSortMode(SortDocument)
+	assert.Equal(t, []string{"ip", "location", "asn", "aardvark", "zebra", "alpha"}, sortedValues(m.nodes))
+	assert.Equal(t, "asn", m.currentNode.Value)
+}
@@ -0,0 +1,108 @@
+package tree
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// crumbSeparator is drawn between the nodes of the breadcrumb
+	crumbSeparator = " › "
+	// zoomMarker starts the breadcrumb when the tree is zoomed into a subtree
+	zoomMarker = "⤢ "
+	// crumbLabels are the keys that jump to each ancestor, from the top level down
+	crumbLabels = "123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+// breadcrumb is the header showing the ancestors of the selected node.
+type breadcrumb struct {
+	// jumping is set while waiting for the label of the ancestor to jump to
+	jumping bool
+}
+
+// Jumping returns true while the breadcrumb labels are shown, waiting for the
+// key of the ancestor to select.
+func (m *Model) Jumping() bool {
+	return m.breadcrumb.jumping
+}
+
+// StartJump labels the ancestors in the breadcrumb so that they can be
+// selected with a single key.
+func (m *Model) StartJump() {
+	if m.currentNode != nil && len(m.viewPath(m.currentNode)) > 1 {
+		m.breadcrumb.jumping = true
+	}
+}
+
+// JumpToAncestor selects the ancestor of the selected node at depth, where the
+// top of the tree, or the zoomed node, is depth 0. It returns false if there is
+// no such ancestor.
+func (m *Model) JumpToAncestor(depth int) bool {
+	path := m.viewPath(m.currentNode)
+	if depth < 0 || depth >= len(path)-1 {
+		return false
+	}
+	m.selectNode(path[depth])
+	return true
+}
+
+// updateJump handles the key pressed after StartJump.
+func (m *Model) updateJump(msg tea.KeyMsg) tea.Cmd {
+	m.breadcrumb.jumping = false
+	if key.Matches(msg, m.KeyMap.CancelJump) {
+		return nil
+	}
+	if s := msg.String(); len(s) == 1 {
+		if depth := strings.Index(crumbLabels, s); depth >= 0 && m.JumpToAncestor(depth) {
+			return nil
+		}
+	}
+	m.status = "no ancestor " + msg.String()
+	return nil
+}
+
+// crumbText returns the name of node in the breadcrumb, with array indices in brackets.
+func crumbText(node *Node) string {
+	if node.InArray {
+		return "[" + cellText(node.Value) + "]"
+	}
+	return cellText(node.Value)
+}
+
+// breadcrumbView renders the path to the selected node on one line. When it is
+// too wide the start is cut off, keeping the nodes closest to the selection.
+// The path to the zoomed node, which is outside of the view, is shown first.
+func (m *Model) breadcrumbView() string {
+	if m.currentNode == nil {
+		return ""
+	}
+	path := m.viewPath(m.currentNode)
+	parts := make([]string, 0, len(path))
+	for depth, node := range path {
+		crumb := m.Styles.Breadcrumb.Render(crumbText(node))
+		if depth == len(path)-1 {
+			crumb = m.Styles.Selected.Render(crumbText(node))
+		} else if m.breadcrumb.jumping && depth < len(crumbLabels) {
+			crumb = m.Styles.Match.Render(crumbLabels[depth:depth+1]) + " " + crumb
+		}
+		parts = append(parts, crumb)
+	}
+	s := strings.Join(parts, m.Styles.Shapes.Render(crumbSeparator))
+	if m.zoom.root != nil {
+		// the nodes above the zoomed node are marked, as they are outside the view
+		var outside []string
+		for ancestor := m.zoom.root.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			outside = append(outside, crumbText(ancestor)+crumbSeparator)
+		}
+		slices.Reverse(outside)
+		s = m.Styles.Badge.Render(zoomMarker+strings.Join(outside, "")) + s
+	}
+	if over := ansi.StringWidth(s) - m.width; over > 0 {
+		s = ansi.Truncate(ansi.TruncateLeft(s, over+ansi.StringWidth(ellipsis), ellipsis), m.width, "")
+	}
+	return s
+}
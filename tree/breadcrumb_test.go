@@ -0,0 +1,64 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreadcrumb(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	latitude := m.nodes[1].Children[2].Children[0]
+	m.SelectNode(latitude)
+	assert.Equal(t, "location › coordinates › latitude", ansi.Strip(m.breadcrumbView()))
+	assert.Equal(t, "location › coordinates › latitude", strings.TrimSpace(strings.Split(ansi.Strip(m.View()), "\n")[0]))
+
+	// the start of the path is cut off to fit
+	m.SetSize(20, 24)
+	assert.Equal(t, "…rdinates › latitude", ansi.Strip(m.breadcrumbView()))
+	m.SetSize(5, 24)
+	assert.Equal(t, "…tude", ansi.Strip(m.breadcrumbView()))
+
+	m.SelectNode(&Node{})
+	m.SetSize(80, 24)
+	m.ShowBreadcrumb = false
+	assert.Equal(t, "ip", strings.Fields(ansi.Strip(m.View()))[0])
+
+	array := []*Node{{Value: "items", Children: []*Node{{Value: "0", InArray: true}}}}
+	m = New(array, 80, 24)
+	m.ExpandAll()
+	m.NavDown()
+	assert.Equal(t, "items › [0]", ansi.Strip(m.breadcrumbView()))
+}
+
+func TestJumpToAncestor(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SelectNode(m.nodes[1].Children[2].Children[0])
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	assert.True(t, m.Prompting())
+	assert.Equal(t, "1 location › 2 coordinates › latitude", ansi.Strip(m.breadcrumbView()))
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	assert.False(t, m.Jumping())
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+
+	// the selected node and deeper levels are not ancestors
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+	assert.Equal(t, "no ancestor 2", m.status)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.Jumping())
+	assert.Equal(t, "", m.status)
+	assert.True(t, m.JumpToAncestor(0))
+	assert.Equal(t, "location", m.SelectedNode().Value)
+
+	// top level nodes have no ancestors to jump to
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	assert.False(t, m.Jumping())
+}
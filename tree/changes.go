@@ -0,0 +1,198 @@
+package tree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Change is how a node differs from the document it is compared with.
+type Change int
+
+const (
+	// ChangeNone is an unchanged node
+	ChangeNone Change = iota
+	// ChangeAdded is a node only in the new document
+	ChangeAdded
+	// ChangeRemoved is a node only in the old document
+	ChangeRemoved
+	// ChangeChanged is a node whose value differs between the documents
+	ChangeChanged
+	// ChangeMoved is an array element which is in a different position in the new document
+	ChangeMoved
+)
+
+var (
+	changeNames   = []string{"unchanged", "added", "removed", "changed", "moved"}
+	changeMarkers = []string{" ", "+", "-", "~", "↕"}
+)
+
+// gutterWidth is the width of each column of markers drawn before the nodes
+const gutterWidth = 2
+
+func (c Change) String() string {
+	if int(c) < len(changeNames) {
+		return changeNames[c]
+	}
+	return fmt.Sprintf("Change(%d)", int(c))
+}
+
+// Marker returns the symbol drawn beside nodes with the change.
+func (c Change) Marker() string {
+	if int(c) < len(changeMarkers) {
+		return changeMarkers[c]
+	}
+	return " "
+}
+
+// changes holds the counts shown in the summary of the changes.
+type changes struct {
+	counts map[Change]int
+}
+
+// SetShowChanges sets whether the change markers, summary and navigation are
+// shown, for trees built from a diff.
+func (m *Model) SetShowChanges(show bool) {
+	m.ShowChanges = show
+	m.countChanges()
+}
+
+// SetChangesOnly hides every node which is unchanged and has no changed descendant.
+func (m *Model) SetChangesOnly(only bool) {
+	m.filter.changesOnly = only
+	m.SetFilter(m.filter.query)
+}
+
+// ChangesOnly returns true if unchanged nodes are hidden.
+func (m *Model) ChangesOnly() bool {
+	return m.filter.changesOnly
+}
+
+// NextChange moves the cursor to the next change after the selected node, wrapping at the end.
+func (m *Model) NextChange() {
+	m.moveToChange(1)
+}
+
+// PrevChange moves the cursor to the previous change before the selected node, wrapping at the start.
+func (m *Model) PrevChange() {
+	m.moveToChange(-1)
+}
+
+// moveToChange selects the closest change in direction step from the selected
+// node, including those in collapsed subtrees.
+func (m *Model) moveToChange(step int) {
+	found := m.findChanges()
+	if len(found) == 0 {
+		m.status = "no changes"
+		return
+	}
+	// next is the index of the first change after the selected node
+	next := len(found)
+	order := 0
+	walkNodes(m.roots(), nil, func(node *Node, _ []*Node) {
+		if node == m.currentNode {
+			next = order
+		}
+		if order < len(found) && node == found[order] {
+			order++
+		}
+	})
+	idx := next
+	if next < len(found) && found[next] == m.currentNode {
+		// the selected node is itself a change, so skip over it
+		idx = next + step
+	} else if step < 0 {
+		idx = next - 1
+	}
+	idx = (idx + len(found)) % len(found)
+	m.expandAncestors(found[idx])
+	m.selectNode(found[idx])
+	m.status = "change " + strconv.Itoa(idx+1) + "/" + strconv.Itoa(len(found))
+}
+
+// findChanges returns the shown nodes in the view where a change starts, in
+// display order.
+func (m *Model) findChanges() []*Node {
+	found := make([]*Node, 0)
+	walkNodes(m.roots(), nil, func(node *Node, _ []*Node) {
+		if isChangeStart(node) && m.shown(node) {
+			found = append(found, node)
+		}
+	})
+	return found
+}
+
+// isChangeStart returns true if node is changed and is not inside a subtree
+// with the same change, such as the descendants of an added node.
+func isChangeStart(node *Node) bool {
+	return node.Change != ChangeNone && (node.Parent == nil || node.Parent.Change != node.Change)
+}
+
+// countChanges counts the changes in the tree for the summary.
+func (m *Model) countChanges() {
+	m.changes = changes{counts: make(map[Change]int)}
+	if !m.ShowChanges {
+		return
+	}
+	walkNodes(m.nodes, nil, func(node *Node, _ []*Node) {
+		if isChangeStart(node) {
+			m.changes.counts[node.Change]++
+		}
+	})
+}
+
+// changeStyle returns the style for nodes with the change.
+func (m *Model) changeStyle(c Change) lipgloss.Style {
+	switch c {
+	case ChangeAdded:
+		return m.Styles.Added
+	case ChangeRemoved:
+		return m.Styles.Removed
+	case ChangeChanged:
+		return m.Styles.Changed
+	case ChangeMoved:
+		return m.Styles.Moved
+	}
+	return m.Styles.Unselected
+}
+
+// gutter returns the pick and change markers drawn before node, for those
+// which are shown.
+func (m *Model) gutter(node *Node) string {
+	var s string
+	if m.MultiPick {
+		s += m.pickGutter(node)
+	}
+	if m.ShowChanges {
+		s += m.changeStyle(node.Change).Render(pad(node.Change.Marker(), gutterWidth))
+	}
+	return s
+}
+
+// gutterWidth returns the width of the markers drawn before each node.
+func (m *Model) gutterWidth() int {
+	width := 0
+	if m.MultiPick {
+		width += gutterWidth
+	}
+	if m.ShowChanges {
+		width += gutterWidth
+	}
+	return width
+}
+
+// changeSummary describes the number of each kind of change.
+func (m *Model) changeSummary() string {
+	parts := make([]string, 0, len(changeNames))
+	for c := ChangeAdded; int(c) < len(changeNames); c++ {
+		if n := m.changes.counts[c]; n > 0 {
+			parts = append(parts, m.changeStyle(c).Render(c.Marker()+strconv.Itoa(n)+" "+c.String()))
+		}
+	}
+	if len(parts) == 0 {
+		return m.Styles.Help.Render("no changes")
+	}
+	return strings.Join(parts, m.Styles.Help.Render("  "))
+}
@@ -0,0 +1,109 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func changedNodes() []*Node {
+	return []*Node{
+		{Value: "name", Desc: "api"},
+		{Value: "version", Desc: "1 → 2", Change: ChangeChanged},
+		{Value: "servers", Desc: "a b", Expand: true, Children: []*Node{
+			{Value: "0", Desc: "a", InArray: true},
+			{Value: "1", Desc: "from 0: b", InArray: true, Change: ChangeMoved},
+			{Value: "2", Desc: "c", InArray: true, Change: ChangeAdded, Children: []*Node{
+				{Value: "id", Desc: "c", Change: ChangeAdded},
+			}},
+		}},
+		{Value: "old", Desc: "true", Change: ChangeRemoved},
+	}
+}
+
+func TestChangeMarkers(t *testing.T) {
+	m := New(changedNodes(), 80, 24)
+	m.SetShowChanges(true)
+	lines := strings.Split(ansi.Strip(m.renderTree(m.Height())), "\n")
+	assert.Equal(t, []string{
+		"  name     api",
+		"~ version  1 → 2",
+		"  servers  a b",
+		"  ├── 0    a",
+		"↕ ├── 1    from 0: b",
+		"+ └── 2    c",
+		"- old      true",
+	}, lines)
+	assert.Equal(t, "+1 added  -1 removed  ~1 changed  ↕1 moved", ansi.Strip(m.changeSummary()))
+	assert.Equal(t, m.Styles.Added, m.valueStyle(m.nodes[2].Children[2]))
+
+	// the markers are drawn before the guides, which fold the node when clicked
+	click(m, 2, 5)
+	assert.True(t, m.nodes[2].Children[2].Expand)
+	assert.Equal(t, "name", m.SelectedNode().Value)
+
+	m.SetShowChanges(false)
+	lines = strings.Split(ansi.Strip(m.renderTree(m.Height())), "\n")
+	assert.Equal(t, "version     1 → 2", lines[1])
+	assert.Equal(t, "no changes", ansi.Strip(m.changeSummary()))
+}
+
+func TestNextChange(t *testing.T) {
+	m := New(changedNodes(), 80, 24)
+	m.SetShowChanges(true)
+	m.SetExpand(m.nodes[2], false)
+
+	next := func() string {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">")})
+		return m.SelectedNode().Value
+	}
+	prev := func() string {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("<")})
+		return m.SelectedNode().Value
+	}
+	assert.Equal(t, "version", next())
+	assert.Equal(t, "change 1/4", m.status)
+	// changes in collapsed subtrees are found, but not the descendants of an added node
+	assert.Equal(t, "1", next())
+	assert.True(t, m.nodes[2].Expand)
+	assert.Equal(t, "2", next())
+	assert.Equal(t, "old", next())
+	assert.Equal(t, "version", next())
+	assert.Equal(t, "old", prev())
+	assert.Equal(t, "2", prev())
+
+	// between changes, the closest in each direction is chosen
+	m.SelectNode(m.nodes[2].Children[0])
+	assert.Equal(t, "version", prev())
+	m.SelectNode(m.nodes[2].Children[0])
+	assert.Equal(t, "1", next())
+
+	m = New(testNodes(), 80, 24)
+	m.SetShowChanges(true)
+	m.NextChange()
+	assert.Equal(t, "no changes", m.status)
+}
+
+func TestChangesOnly(t *testing.T) {
+	m := New(changedNodes(), 80, 24)
+	m.SetShowChanges(true)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	assert.True(t, m.ChangesOnly())
+	assert.Equal(t, []string{"0:version", "0:servers", "1:1", "1:2", "2:id", "0:old"}, rowValues(m))
+	assert.Equal(t, "only changes", ansi.Strip(m.filterView()))
+
+	// the text filter applies to the changed nodes
+	m.SetFilter("id")
+	assert.Equal(t, []string{"0:servers", "1:2", "2:id"}, rowValues(m))
+	assert.Contains(t, ansi.Strip(m.filterView()), "only changes")
+	m.ClearFilter()
+	assert.Equal(t, []string{"0:version", "0:servers", "1:1", "1:2", "2:id", "0:old"}, rowValues(m))
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	assert.False(t, m.ChangesOnly())
+	assert.Equal(t, 7, m.NumberOfNodes())
+	assert.Equal(t, "", m.filterView())
+}
@@ -0,0 +1,103 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CopiedMsg reports the result of copying to the clipboard.
+type CopiedMsg struct {
+	// Description is shown in the status line once the copy succeeds
+	Description string
+	Err         error
+}
+
+// Copy returns a command copying text to the system clipboard by writing to
+// stdout. It uses an OSC52 escape sequence, so works over SSH in terminals
+// which support it.
+func Copy(text string, description string) tea.Cmd {
+	return CopyTo(os.Stdout, text, description)
+}
+
+// CopyTo returns a command copying text to the system clipboard by writing to
+// w, which should be the terminal the program renders to. The escape sequence
+// is written at once, so it cannot be split by a frame being drawn.
+func CopyTo(w io.Writer, text string, description string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(text)
+		switch {
+		case os.Getenv("TMUX") != "":
+			seq = seq.Tmux()
+		case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+			seq = seq.Screen()
+		}
+		_, err := io.WriteString(w, seq.String())
+		return CopiedMsg{Description: description, Err: err}
+	}
+}
+
+// copy copies text to the clipboard through the output of the model.
+func (m *Model) copy(text string, description string) tea.Cmd {
+	if m.Output == nil {
+		return Copy(text, description)
+	}
+	return CopyTo(m.Output, text, description)
+}
+
+// CopyPath copies the path of the selected node in the model's path format.
+func (m *Model) CopyPath() tea.Cmd {
+	if m.currentNode == nil {
+		return nil
+	}
+	path := m.currentNode.FormatPath(m.PathFormat)
+	return m.copy(path, "copied "+path)
+}
+
+// CyclePathFormat switches to the next path format.
+func (m *Model) CyclePathFormat() {
+	m.PathFormat = m.PathFormat.Next()
+	m.status = "path format: " + m.PathFormat.String()
+}
+
+// CopyValue copies the raw value of the selected node, writing strings without
+// quotes and objects and arrays as indented JSON.
+func (m *Model) CopyValue() tea.Cmd {
+	if m.currentNode == nil {
+		return nil
+	}
+	if s, ok := m.currentNode.Data.(string); ok {
+		return m.copy(s, "copied value of "+m.currentNode.Value)
+	}
+	return m.CopyJSON()
+}
+
+// CopyJSON copies the selected node and all of its descendants as indented JSON.
+func (m *Model) CopyJSON() tea.Cmd {
+	if m.currentNode == nil {
+		return nil
+	}
+	out, err := marshalIndent(m.currentNode.Data)
+	if err != nil {
+		m.status = "copy failed: " + err.Error()
+		return nil
+	}
+	return m.copy(out, "copied "+m.currentNode.Value+" as JSON")
+}
+
+// marshalIndent encodes v as indented JSON without escaping HTML characters.
+func marshalIndent(v any) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
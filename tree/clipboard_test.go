@@ -0,0 +1,59 @@
+package tree
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalIndent(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"null", nil, "null"},
+		{"number", 153.0175, "153.0175"},
+		{"string", "<a&b>", `"<a&b>"`},
+		{"object", map[string]any{"ports": []any{80.0, 443.0}}, "{\n  \"ports\": [\n    80,\n    443\n  ]\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshalIndent(tt.v)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCopyWithoutSelection(t *testing.T) {
+	m := New(nil, 80, 24)
+	assert.Nil(t, m.CopyPath())
+	assert.Nil(t, m.CopyValue())
+	assert.Nil(t, m.CopyJSON())
+}
+
+func TestCopyToOutput(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm")
+	var out strings.Builder
+	m := New(testNodes(), 80, 24)
+	m.Output = &out
+	msg := m.CopyPath()().(CopiedMsg)
+	assert.NoError(t, msg.Err)
+	assert.Equal(t, "copied $.ip", msg.Description)
+	assert.Equal(t, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte("$.ip"))+"\a", out.String())
+
+	// failing to write is reported rather than claiming the copy succeeded
+	m.Output = errWriter{}
+	assert.Error(t, m.CopyValue()().(CopiedMsg).Err)
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("closed")
+}
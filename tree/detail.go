@@ -0,0 +1,216 @@
+package tree
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// detailSideWidth is the narrowest view with the detail pane beside the
+	// tree, below which it is shown underneath instead
+	detailSideWidth = 80
+	// detailRows is the number of lines of metadata above the value
+	detailRows = 3
+)
+
+// detail is a pane showing the complete value of the selected node.
+type detail struct {
+	open bool
+	// wrap breaks long lines at the width of the pane instead of cutting them off
+	wrap     bool
+	viewport viewport.Model
+
+	// node, width and wrapped are what the content was last rendered for
+	node    *Node
+	width   int
+	wrapped bool
+}
+
+func newDetail() detail {
+	return detail{wrap: true, viewport: viewport.New(0, 0)}
+}
+
+// DetailOpen returns true if the detail pane is shown.
+func (m *Model) DetailOpen() bool {
+	return m.detail.open
+}
+
+// ToggleDetail shows or hides the detail pane.
+func (m *Model) ToggleDetail() {
+	m.detail.open = !m.detail.open
+}
+
+// ToggleDetailWrap switches between wrapping and cutting off long lines in the detail pane.
+func (m *Model) ToggleDetailWrap() {
+	m.detail.wrap = !m.detail.wrap
+	if m.detail.wrap {
+		m.status = "detail: wrap"
+	} else {
+		m.status = "detail: no wrap"
+	}
+}
+
+// ScrollDetail scrolls the detail pane by delta lines.
+func (m *Model) ScrollDetail(delta int) {
+	if delta > 0 {
+		m.detail.viewport.LineDown(delta)
+	} else {
+		m.detail.viewport.LineUp(-delta)
+	}
+}
+
+// detailSide returns true if the detail pane is beside the tree rather than below it.
+func (m *Model) detailSide() bool {
+	return m.width >= detailSideWidth
+}
+
+// treeWidth returns the width available to the tree.
+func (m *Model) treeWidth() int {
+	if m.detail.open && m.detailSide() {
+		return m.width - m.width*2/5
+	}
+	return m.width
+}
+
+// detailView renders the pane for the selected node, width by height cells
+// including its border.
+func (m *Model) detailView(width, height int) string {
+	style := m.Styles.Detail
+	if m.detailSide() {
+		style = style.Border(lipgloss.NormalBorder(), false, false, false, true).PaddingLeft(1)
+	} else {
+		style = style.Border(lipgloss.NormalBorder(), true, false, false, false)
+	}
+	style = style.Width(width - style.GetHorizontalBorderSize()).Height(height - style.GetVerticalBorderSize())
+	width -= style.GetHorizontalFrameSize()
+	height -= style.GetVerticalFrameSize()
+	node := m.currentNode
+	if width <= 0 || height <= 0 || node == nil {
+		return ""
+	}
+
+	vp := &m.detail.viewport
+	vp.Width = width
+	vp.Height = max(height-detailRows, 0)
+	if node != m.detail.node || width != m.detail.width || m.detail.wrap != m.detail.wrapped {
+		if node != m.detail.node {
+			vp.GotoTop()
+		}
+		m.detail.node, m.detail.width, m.detail.wrapped = node, width, m.detail.wrap
+		content := m.detailContent(node)
+		if m.detail.wrap {
+			content = ansi.Wrap(content, width, "")
+		} else {
+			lines := strings.Split(content, "\n")
+			for i, line := range lines {
+				lines[i] = ansi.Truncate(line, width, ellipsis)
+			}
+			content = strings.Join(lines, "\n")
+		}
+		vp.SetContent(content)
+	}
+
+	meta := []string{
+		truncate(node.FormatPath(m.PathFormat), width),
+		truncate(m.detailMetadata(node), width),
+		"",
+	}
+	return style.Render(lipgloss.JoinVertical(lipgloss.Left,
+		m.Styles.Help.Render(strings.Join(meta, "\n")),
+		vp.View(),
+	))
+}
+
+// detailMetadata describes the type, size and depth of node.
+func (m *Model) detailMetadata(node *Node) string {
+	parts := []string{"type: " + node.Type.String()}
+	if len(node.Children) > 0 {
+		parts = append(parts, "children: "+strconv.Itoa(len(node.Children)))
+	}
+	parts = append(parts, "depth: "+strconv.Itoa(len(node.Path())-1))
+	if m.detail.viewport.TotalLineCount() > m.detail.viewport.Height {
+		parts = append(parts, strconv.Itoa(int(m.detail.viewport.ScrollPercent()*100))+"%")
+	}
+	return strings.Join(parts, "  ")
+}
+
+// detailContent returns the complete value of node. Strings are shown as they
+// are and anything else as highlighted, indented JSON.
+func (m *Model) detailContent(node *Node) string {
+	switch data := node.Data.(type) {
+	case string:
+		return m.Styles.String.Render(data)
+	case nil:
+		if node.Type != TypeNull {
+			// nodes built without data only have their description
+			return node.Desc
+		}
+	}
+	out, err := marshalIndent(node.Data)
+	if err != nil {
+		return node.Desc
+	}
+	return m.highlightJSON(out)
+}
+
+// highlightJSON styles the keys and values of indented JSON by type.
+func (m *Model) highlightJSON(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(s))
+			style := m.Styles.String
+			if strings.HasPrefix(s[end:], ":") {
+				style = m.Styles.Unselected
+			}
+			b.WriteString(style.Render(s[i:end]))
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(s) && strings.IndexByte("0123456789.eE+-", s[end]) >= 0 {
+				end++
+			}
+			b.WriteString(m.Styles.Number.Render(s[i:end]))
+			i = end
+		case strings.HasPrefix(s[i:], "true"), strings.HasPrefix(s[i:], "false"):
+			end := i + 4
+			if c == 'f' {
+				end++
+			}
+			b.WriteString(m.Styles.Boolean.Render(s[i:end]))
+			i = end
+		case strings.HasPrefix(s[i:], "null"):
+			b.WriteString(m.Styles.Null.Render("null"))
+			i += 4
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// inDetail returns true if the point x, y relative to the top left of the tree
+// is inside the detail pane.
+func (m *Model) inDetail(x, y int) bool {
+	if !m.detail.open {
+		return false
+	}
+	if m.detailSide() {
+		return x >= m.treeWidth()
+	}
+	return y >= m.scroll.height
+}
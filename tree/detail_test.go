@@ -0,0 +1,99 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func detailNodes() []*Node {
+	nodes := typedNodes()
+	nodes[5].Data = map[string]any{"city": "London", "zip": nil, "floors": []any{1, true}}
+	return nodes
+}
+
+func TestDetailLayout(t *testing.T) {
+	m := New(detailNodes(), 100, 20)
+	m.ToggleDetail()
+	assert.True(t, m.DetailOpen())
+	assert.Equal(t, 60, m.treeWidth())
+
+	lines := strings.Split(ansi.Strip(m.mainView(20)), "\n")
+	assert.Len(t, lines, 20)
+	assert.True(t, strings.HasPrefix(lines[0], `name     "Ada"`))
+	assert.Contains(t, lines[0], "│ $.name")
+	assert.Contains(t, lines[1], "type: string  depth: 0")
+	assert.Contains(t, lines[3], "Ada")
+	for _, line := range lines {
+		assert.LessOrEqual(t, ansi.StringWidth(line), 100)
+	}
+
+	// narrow views show the detail below the tree
+	m.SetSize(60, 20)
+	assert.Equal(t, 60, m.treeWidth())
+	lines = strings.Split(ansi.Strip(m.mainView(20)), "\n")
+	assert.Len(t, lines, 20)
+	assert.Equal(t, strings.Repeat("─", 60), lines[10])
+	assert.Equal(t, "$.name", strings.TrimSpace(lines[11]))
+
+	m.ToggleDetail()
+	assert.Equal(t, 60, m.treeWidth())
+}
+
+func TestDetailContent(t *testing.T) {
+	m := New(detailNodes(), 100, 20)
+	m.SelectNode(m.nodes[5])
+	content := ansi.Strip(m.detailContent(m.currentNode))
+	assert.Equal(t, "{\n  \"city\": \"London\",\n  \"floors\": [\n    1,\n    true\n  ],\n  \"zip\": null\n}", content)
+	assert.Equal(t, "type: object  children: 1  depth: 0", m.detailMetadata(m.currentNode))
+
+	m.SelectNode(m.nodes[5].Children[0])
+	assert.Equal(t, "London", ansi.Strip(m.detailContent(m.currentNode)))
+	assert.Equal(t, "type: string  depth: 1", m.detailMetadata(m.currentNode))
+
+	// nodes without data show their description
+	assert.Equal(t, "a b", m.detailContent(m.nodes[4]))
+	assert.Equal(t, "null", ansi.Strip(m.detailContent(m.nodes[3])))
+}
+
+func TestHighlightJSON(t *testing.T) {
+	m := New(nil, 80, 24)
+	s := `{"a\"": "x:", "n": -1.5e3, "t": false, "z": null}`
+	assert.Equal(t, s, ansi.Strip(m.highlightJSON(s)))
+	assert.Contains(t, m.highlightJSON(s), m.Styles.Number.Render("-1.5e3"))
+	assert.Contains(t, m.highlightJSON(s), m.Styles.Boolean.Render("false"))
+	assert.Contains(t, m.highlightJSON(s), m.Styles.String.Render(`"x:"`))
+	assert.Contains(t, m.highlightJSON(s), m.Styles.Unselected.Render(`"a\""`))
+}
+
+func TestDetailWrapAndScroll(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	m := New([]*Node{{Value: "text", Desc: long, Data: long, Type: TypeString}, {Value: "other", Desc: "x", Data: "x"}}, 100, 10)
+	m.showHelp = false
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m.View()
+	assert.Equal(t, 15, m.detail.viewport.TotalLineCount())
+	assert.Equal(t, 0, m.detail.viewport.YOffset)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	assert.Equal(t, 1, m.detail.viewport.YOffset)
+	m.Update(tea.MouseMsg{X: 70, Y: 5, Button: tea.MouseButtonWheelUp})
+	assert.Equal(t, 0, m.detail.viewport.YOffset)
+	assert.Equal(t, "text", m.SelectedNode().Value)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m.View()
+	assert.Equal(t, 1, m.detail.viewport.TotalLineCount())
+	assert.Equal(t, "detail: no wrap", m.status)
+
+	// scrolling is reset when another node is selected
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m.View()
+	m.ScrollDetail(2)
+	m.NavDown()
+	m.View()
+	assert.Equal(t, 0, m.detail.viewport.YOffset)
+}
@@ -0,0 +1,46 @@
+package tree
+
+// ExpandAll expands every node in the tree, or the zoomed subtree.
+func (m *Model) ExpandAll() {
+	m.setExpand(m.roots(), true)
+}
+
+// CollapseAll collapses every node in the tree, moving the cursor to the top
+// level ancestor of the selected node.
+func (m *Model) CollapseAll() {
+	m.setExpand(m.roots(), false)
+}
+
+// ExpandSubtree expands the selected node and all of its descendants.
+func (m *Model) ExpandSubtree() {
+	if node := m.currentNode; node != nil {
+		m.setExpand([]*Node{node}, true)
+	}
+}
+
+// CollapseSubtree collapses the selected node and all of its descendants.
+func (m *Model) CollapseSubtree() {
+	if node := m.currentNode; node != nil {
+		m.setExpand([]*Node{node}, false)
+	}
+}
+
+// ExpandToDepth expands the nodes above depth and collapses the rest, so that
+// depth levels of the tree are shown below the top level nodes. A depth of 0
+// collapses everything.
+func (m *Model) ExpandToDepth(depth int) {
+	walkNodes(m.roots(), nil, func(node *Node, ancestors []*Node) {
+		node.Expand = len(ancestors) < depth
+	})
+	m.invalidateRows()
+	m.selectNode(m.currentNode)
+}
+
+// setExpand sets the expand state of nodes and all of their descendants.
+func (m *Model) setExpand(nodes []*Node, expand bool) {
+	walkNodes(nodes, nil, func(node *Node, _ []*Node) {
+		node.Expand = expand
+	})
+	m.invalidateRows()
+	m.selectNode(m.currentNode)
+}
@@ -0,0 +1,57 @@
+package tree
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandAll(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.ExpandAll()
+	assert.Equal(t, 8, m.NumberOfNodes())
+
+	m.MoveCursor(5)
+	assert.Equal(t, "latitude", m.currentNode.Value)
+	m.CollapseAll()
+	assert.Equal(t, 3, m.NumberOfNodes())
+	assert.Equal(t, "location", m.currentNode.Value)
+	assert.Equal(t, 1, m.Cursor())
+}
+
+func TestExpandSubtree(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.MoveCursor(1)
+	m.ExpandSubtree()
+	assert.Equal(t, 8, m.NumberOfNodes())
+
+	m.MoveCursor(3)
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+	m.MoveCursor(-3)
+	m.CollapseSubtree()
+	assert.Equal(t, 3, m.NumberOfNodes())
+	assert.False(t, m.nodes[1].Children[2].Expand)
+	assert.Equal(t, "location", m.currentNode.Value)
+}
+
+func TestExpandToDepth(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.ExpandAll()
+	m.MoveCursor(6)
+	assert.Equal(t, "longitude", m.currentNode.Value)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+	// ip, location, continent, country, coordinates, asn
+	assert.Equal(t, 6, m.NumberOfNodes())
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+	assert.Equal(t, 4, m.Cursor())
+
+	m.ExpandToDepth(2)
+	assert.Equal(t, 8, m.NumberOfNodes())
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+
+	m.ExpandToDepth(0)
+	assert.Equal(t, 3, m.NumberOfNodes())
+	assert.Equal(t, "location", m.currentNode.Value)
+}
@@ -0,0 +1,254 @@
+package tree
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// matcher tests strings against a filter query.
+type matcher struct {
+	query      string
+	re         *regexp.Regexp
+	ignoreCase bool
+}
+
+// newMatcher creates a matcher for query, compiling it if regex is set.
+func newMatcher(query string, regex bool, ignoreCase bool) (matcher, error) {
+	m := matcher{query: query, ignoreCase: ignoreCase}
+	if regex {
+		if ignoreCase {
+			query = "(?i)" + query
+		}
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return m, err
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+func (m matcher) match(s string) bool {
+	switch {
+	case m.re != nil:
+		return m.re.MatchString(s)
+	case m.ignoreCase:
+		return indexFold(s, m.query) >= 0
+	default:
+		return strings.Contains(s, m.query)
+	}
+}
+
+type filter struct {
+	input      textinput.Model
+	query      string
+	regex      bool
+	ignoreCase bool
+	err        error
+	// changesOnly hides the nodes of a diff which are unchanged
+	changesOnly bool
+	// visible holds the nodes which match or have a matching descendant
+	visible map[*Node]bool
+	// expanded and selected store the state prior to filtering so it can be restored
+	expanded map[*Node]bool
+	selected *Node
+}
+
+func newFilter() filter {
+	input := textinput.New()
+	input.Prompt = "filter: "
+	return filter{input: input, ignoreCase: true}
+}
+
+// EditingFilter returns true while the filter prompt is capturing key presses.
+func (m *Model) EditingFilter() bool {
+	return m.filter.input.Focused()
+}
+
+// Filtered returns true if the tree is currently pruned by a filter.
+func (m *Model) Filtered() bool {
+	return m.filter.visible != nil
+}
+
+// FilterQuery returns the active filter query.
+func (m *Model) FilterQuery() string {
+	return m.filter.query
+}
+
+// StartFilter opens the filter prompt.
+func (m *Model) StartFilter() tea.Cmd {
+	m.filter.input.SetValue(m.filter.query)
+	m.filter.input.CursorEnd()
+	return m.filter.input.Focus()
+}
+
+// SetFilterRegex sets whether the filter query is a regular expression.
+func (m *Model) SetFilterRegex(regex bool) {
+	m.filter.regex = regex
+	m.SetFilter(m.filter.query)
+}
+
+// SetFilterIgnoreCase sets whether the filter ignores case.
+func (m *Model) SetFilterIgnoreCase(ignoreCase bool) {
+	m.filter.ignoreCase = ignoreCase
+	m.SetFilter(m.filter.query)
+}
+
+// SetFilter hides every node which neither matches query nor has a matching
+// descendant. An empty query clears the filter, unless only changes are shown.
+func (m *Model) SetFilter(query string) {
+	m.filter.query = query
+	if query == "" && !m.filter.changesOnly {
+		m.restoreFilterState()
+		return
+	}
+	match, err := m.filterMatch()
+	m.filter.err = err
+	if err != nil {
+		// keep the previous result until the expression is valid again
+		return
+	}
+	if !m.Filtered() {
+		m.saveFilterState()
+	} else {
+		// only the ancestors of nodes matching the current query stay expanded
+		for node, expand := range m.filter.expanded {
+			node.Expand = expand
+		}
+	}
+	m.filter.visible = make(map[*Node]bool)
+	m.prune(m.nodes, match)
+	m.invalidateRows()
+	m.selectNode(m.currentNode)
+	m.findMatches()
+}
+
+// filterMatch returns the test for the nodes kept by the active filter.
+func (m *Model) filterMatch() (func(node *Node) bool, error) {
+	query, changesOnly := m.filter.query, m.filter.changesOnly
+	match, err := newMatcher(query, m.filter.regex, m.filter.ignoreCase)
+	if err != nil {
+		return nil, err
+	}
+	return func(node *Node) bool {
+		if changesOnly && node.Change == ChangeNone {
+			return false
+		}
+		return query == "" || match.match(node.Value) || match.match(node.Desc)
+	}, nil
+}
+
+// prune marks the nodes which match or have a matching descendant as visible,
+// expanding those with a matching descendant. It returns true if any node matched.
+func (m *Model) prune(nodes []*Node, match func(node *Node) bool) bool {
+	found := false
+	for _, node := range nodes {
+		descendant := m.prune(node.Children, match)
+		if descendant {
+			node.Expand = true
+		}
+		if descendant || match(node) {
+			m.filter.visible[node] = true
+			found = true
+		}
+	}
+	return found
+}
+
+// ClearFilter removes the active filter, restoring the expand state and cursor from before it was applied.
+func (m *Model) ClearFilter() {
+	m.filter.input.Blur()
+	m.filter.input.Reset()
+	m.SetFilter("")
+}
+
+// saveFilterState records the expand state and selection prior to filtering.
+func (m *Model) saveFilterState() {
+	m.filter.expanded = make(map[*Node]bool)
+	walkNodes(m.nodes, nil, func(node *Node, _ []*Node) {
+		m.filter.expanded[node] = node.Expand
+	})
+	m.filter.selected = m.currentNode
+}
+
+// restoreFilterState reverts to the expand state and selection prior to filtering.
+func (m *Model) restoreFilterState() {
+	m.filter.err = nil
+	if !m.Filtered() {
+		return
+	}
+	m.filter.visible = nil
+	m.invalidateRows()
+	for node, expand := range m.filter.expanded {
+		node.Expand = expand
+	}
+	m.selectNode(m.filter.selected)
+	m.filter.expanded = nil
+	m.filter.selected = nil
+	m.findMatches()
+}
+
+// updateFilter handles key presses while the filter prompt is open.
+func (m *Model) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.KeyMap.AcceptFilter):
+		m.filter.input.Blur()
+		return nil
+	case key.Matches(msg, m.KeyMap.CancelFilter):
+		m.ClearFilter()
+		return nil
+	case key.Matches(msg, m.KeyMap.ToggleRegex):
+		m.SetFilterRegex(!m.filter.regex)
+		return nil
+	case key.Matches(msg, m.KeyMap.ToggleIgnoreCase):
+		m.SetFilterIgnoreCase(!m.filter.ignoreCase)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.filter.input, cmd = m.filter.input.Update(msg)
+	if query := m.filter.input.Value(); query != m.filter.query {
+		m.SetFilter(query)
+	}
+	return cmd
+}
+
+// filterView renders the filter prompt or the active filter.
+func (m *Model) filterView() string {
+	var view string
+	switch {
+	case m.EditingFilter():
+		view = m.filter.input.View()
+	case m.filter.query != "":
+		view = m.Styles.Help.Render(m.filter.input.Prompt + m.filter.query)
+	case m.filter.changesOnly:
+		return m.Styles.Help.Render("only changes")
+	default:
+		return ""
+	}
+	var flags []string
+	if m.filter.changesOnly {
+		flags = append(flags, "only changes")
+	}
+	if m.filter.regex {
+		flags = append(flags, "regex")
+	}
+	if m.filter.ignoreCase {
+		flags = append(flags, "ignore case")
+	}
+	if m.filter.err != nil {
+		flags = append(flags, "invalid regex")
+	}
+	if len(flags) > 0 {
+		view += m.Styles.Help.Render("  [" + strings.Join(flags, ", ") + "]")
+	}
+	return view
+}
+
+// shown returns false if the node is hidden by the active filter.
+func (m *Model) shown(node *Node) bool {
+	return m.filter.visible == nil || m.filter.visible[node]
+}
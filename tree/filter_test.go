@@ -0,0 +1,67 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetCursor(2)
+	assert.Equal(t, "asn", m.currentNode.Value)
+
+	m.SetFilter("LONG")
+	assert.True(t, m.Filtered())
+	// location, coordinates and longitude
+	assert.Equal(t, 3, m.NumberOfNodes())
+	assert.Equal(t, "longitude", m.nodeAt(2).Value)
+	assert.Equal(t, -1, m.indexOf(m.nodes[0]))
+
+	m.SetFilterIgnoreCase(false)
+	assert.Equal(t, 0, m.NumberOfNodes())
+
+	m.SetFilterRegex(true)
+	m.SetFilter("^lat|^ip$")
+	assert.Equal(t, 4, m.NumberOfNodes())
+	assert.Equal(t, "ip", m.nodeAt(0).Value)
+	assert.Equal(t, "latitude", m.nodeAt(3).Value)
+
+	m.SetFilter("(")
+	assert.Error(t, m.filter.err)
+	assert.Equal(t, 4, m.NumberOfNodes())
+
+	m.ClearFilter()
+	assert.False(t, m.Filtered())
+	assert.False(t, m.nodes[1].Expand)
+	assert.False(t, m.nodes[1].Children[2].Expand)
+	assert.Equal(t, 3, m.NumberOfNodes())
+	assert.Equal(t, "asn", m.currentNode.Value)
+	assert.Equal(t, 2, m.Cursor())
+}
+
+func TestFilterSearch(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetFilter("coordinates")
+	m.SetSearch("itude")
+	assert.Len(t, m.search.matches, 0)
+	m.SetFilter("itude")
+	assert.Len(t, m.search.matches, 2)
+	m.ClearFilter()
+	assert.Len(t, m.search.matches, 2)
+}
+
+func TestFilterNarrowed(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetFilter("latitude")
+	assert.True(t, m.nodes[1].Children[2].Expand)
+
+	// coordinates matches itself, so is no longer expanded to show a descendant
+	m.SetFilter("coord")
+	assert.True(t, m.nodes[1].Expand)
+	assert.False(t, m.nodes[1].Children[2].Expand)
+	assert.Equal(t, []string{"0:location", "1:coordinates"}, rowValues(m))
+
+	m.ClearFilter()
+	assert.False(t, m.nodes[1].Expand)
+}
@@ -0,0 +1,90 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Guides are the glyphs drawn before a node to connect it to its parent. Each
+// glyph should have the same display width.
+type Guides struct {
+	// Branch connects a node which has siblings below it
+	Branch string
+	// Last connects the last child of a node
+	Last string
+	// Line continues the line of an ancestor which has siblings below it
+	Line string
+	// Space indents below an ancestor which was the last child
+	Space string
+}
+
+var (
+	// UnicodeGuides draws lines with box drawing characters
+	UnicodeGuides = Guides{Branch: "├── ", Last: "└── ", Line: "│   ", Space: "    "}
+	// RoundedGuides draws lines with box drawing characters and rounded corners
+	RoundedGuides = Guides{Branch: "├── ", Last: "╰── ", Line: "│   ", Space: "    "}
+	// ASCIIGuides draws lines with ASCII characters, for terminals without unicode support
+	ASCIIGuides = Guides{Branch: "|-- ", Last: "`-- ", Line: "|   ", Space: "    "}
+	// NoGuides indents nodes without drawing any lines
+	NoGuides = Guides{Branch: "  ", Last: "  ", Line: "  ", Space: "  "}
+)
+
+// ParseGuides returns the glyph set with the given name: unicode, rounded, ascii or none.
+func ParseGuides(name string) (Guides, error) {
+	switch strings.ToLower(name) {
+	case "unicode", "":
+		return UnicodeGuides, nil
+	case "rounded":
+		return RoundedGuides, nil
+	case "ascii":
+		return ASCIIGuides, nil
+	case "none":
+		return NoGuides, nil
+	}
+	return Guides{}, fmt.Errorf("unknown guides %q, expected unicode, rounded, ascii or none", name)
+}
+
+// guide returns the lines drawn before node, which is at depth below the top level.
+func (m *Model) guide(node *Node, depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	guides := m.Styles.Guides
+	parts := make([]string, depth)
+	if m.lastSibling(node) {
+		parts[depth-1] = guides.Last
+	} else {
+		parts[depth-1] = guides.Branch
+	}
+	// top level nodes have no guide, so stop before reaching them
+	ancestor := node.Parent
+	for i := depth - 2; i >= 0 && ancestor != nil; i-- {
+		if m.lastSibling(ancestor) {
+			parts[i] = guides.Space
+		} else {
+			parts[i] = guides.Line
+		}
+		ancestor = ancestor.Parent
+	}
+	return strings.Join(parts, "")
+}
+
+// lastSibling returns true if no sibling after node is shown.
+func (m *Model) lastSibling(node *Node) bool {
+	siblings := m.nodes
+	if node == m.zoom.root {
+		return true
+	}
+	if node.Parent != nil {
+		siblings = node.Parent.Children
+	}
+	for i := len(siblings) - 1; i >= 0; i-- {
+		if siblings[i] == node {
+			return true
+		}
+		if m.shown(siblings[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+// guideLines renders the tree and returns the guide drawn before each row.
+func guideLines(m *Model) []string {
+	lines := make([]string, 0)
+	for _, r := range m.visibleRows() {
+		lines = append(lines, m.guide(r.node, r.depth)+r.node.Value)
+	}
+	return lines
+}
+
+func TestGuides(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.ExpandAll()
+	assert.Equal(t, []string{
+		"ip",
+		"location",
+		"├── continent",
+		"├── country",
+		"└── coordinates",
+		"    ├── latitude",
+		"    └── longitude",
+		"asn",
+	}, guideLines(m))
+
+	// the lines of open ancestors continue past their children
+	nodes := testNodes()
+	nodes[1].Children[1].Children = []*Node{{Value: "code"}}
+	m = New(nodes, 80, 24)
+	m.ExpandAll()
+	m.Styles.Guides = ASCIIGuides
+	assert.Equal(t, "|   `-- code", guideLines(m)[4])
+
+	// a sibling hidden by the filter does not count as a later sibling
+	m.Styles.Guides = RoundedGuides
+	m.SetFilter("co")
+	assert.Equal(t, []string{
+		"location",
+		"├── continent",
+		"├── country",
+		"│   ╰── code",
+		"╰── coordinates",
+	}, guideLines(m))
+
+	m.Styles.Guides = NoGuides
+	m.ClearFilter()
+	assert.Equal(t, "    latitude", guideLines(m)[6])
+}
+
+func TestParseGuides(t *testing.T) {
+	for _, name := range []string{"unicode", "rounded", "ascii", "none"} {
+		guides, err := ParseGuides(name)
+		assert.NoError(t, err)
+		width := ansi.StringWidth(guides.Branch)
+		for _, glyph := range []string{guides.Last, guides.Line, guides.Space} {
+			assert.Equal(t, width, ansi.StringWidth(glyph), name)
+		}
+	}
+	_, err := ParseGuides("fancy")
+	assert.Error(t, err)
+
+	m := New(testNodes(), 80, 24)
+	m.ExpandAll()
+	assert.True(t, strings.HasPrefix(ansi.Strip(strings.Split(m.renderTree(m.Height()), "\n")[2]), "├── continent"))
+}
@@ -0,0 +1,51 @@
+package tree
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// ellipsis marks text which has been truncated to fit its column
+	ellipsis = "…"
+	// columnGap is the number of cells between the key and value columns
+	columnGap = 2
+)
+
+// cellText replaces the newlines and tabs in s, which would break the layout of a row, with spaces.
+func cellText(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(s)
+}
+
+// truncate shortens s to at most width display cells, ending it with an
+// ellipsis if anything was removed.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return ansi.Truncate(s, width, ellipsis)
+}
+
+// pad truncates s to width display cells and fills the remainder with spaces.
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	if w := ansi.StringWidth(s); w < width {
+		s += strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// columnWidths returns the widths of the key column, including the change
+// marker and guide, and of the value column for rows. The key column fits the
+// widest key, but takes no more than half of the width so that the values
+// remain readable.
+func (m *Model) columnWidths(rows []row) (int, int) {
+	keyWidth := 0
+	for _, r := range rows {
+		keyWidth = max(keyWidth, m.gutterWidth()+ansi.StringWidth(m.guide(r.node, r.depth))+ansi.StringWidth(cellText(r.node.Value)))
+	}
+	width := m.treeWidth()
+	keyWidth = min(keyWidth, max(width/2, 1))
+	return keyWidth, width - keyWidth - columnGap
+}
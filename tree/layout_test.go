@@ -0,0 +1,55 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"hello", 10, "hello"},
+		{"hello world", 6, "hello…"},
+		{"東京都庁", 5, "東京…"},
+		{"東京都庁", 8, "東京都庁"},
+		{"👍👍👍", 4, "👍…"},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.width)
+		assert.Equal(t, tt.want, got, tt.s)
+		assert.LessOrEqual(t, ansi.StringWidth(got), max(tt.width, 0))
+	}
+	assert.Equal(t, "東京  ", pad("東京", 6))
+	assert.Equal(t, "a b c", cellText("a\tb\nc"))
+}
+
+func TestRenderWidth(t *testing.T) {
+	nodes := []*Node{
+		{Value: "name", Desc: "東京スカイツリー is a broadcasting and observation tower"},
+		{Value: "a key which is much longer than the others", Desc: "😀 value"},
+		{Value: "tabs", Desc: "one\ttwo\nthree"},
+	}
+	m := New(nodes, 30, 10)
+	for _, width := range []int{30, 12, 5} {
+		m.Update(tea.WindowSizeMsg{Width: width, Height: 10})
+		assert.Equal(t, width, m.Width())
+		for _, line := range strings.Split(strings.TrimSuffix(m.renderTree(m.Height()), "\n"), "\n") {
+			assert.LessOrEqual(t, ansi.StringWidth(line), width, line)
+			assert.NotContains(t, line, "\t")
+		}
+	}
+
+	m.SetSize(40, 10)
+	lines := strings.Split(m.renderTree(m.Height()), "\n")
+	// keys take at most half the width, and the values line up after them
+	assert.Equal(t, "a key which is much…  😀 value", ansi.Strip(lines[1]))
+	assert.Equal(t, "name                  東京", ansi.Strip(lines[0])[:28])
+}
@@ -0,0 +1,113 @@
+package tree
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// wheelRows is the number of rows scrolled by each turn of the mouse wheel
+	wheelRows = 3
+	// doubleClickInterval is the longest time between the clicks of a double click
+	doubleClickInterval = 400 * time.Millisecond
+)
+
+// scroll tracks the rows drawn in the view.
+type scroll struct {
+	// first is the first row drawn by the last render and height the number of
+	// lines it had, used to find the row under the mouse
+	first  int
+	height int
+	// top is the number of lines drawn above the tree by the last view
+	top int
+	// offset is the first row to draw while active
+	offset int
+	// active is set when the view has been scrolled away from the cursor by the wheel
+	active bool
+
+	// lastClick is the node clicked most recently and when, to detect double clicks
+	lastClick     *Node
+	lastClickTime time.Time
+}
+
+// ScrollView moves the view by delta rows without moving the cursor.
+func (m *Model) ScrollView(delta int) {
+	if !m.scroll.active {
+		m.scroll.active, m.scroll.offset = true, m.scroll.first
+	}
+	m.scroll.offset = max(m.scroll.offset+delta, 0)
+}
+
+// rowAt returns the row drawn on line y of the tree, or -1 if there is none.
+func (m *Model) rowAt(y int) int {
+	if y < 0 || y >= m.scroll.height {
+		return -1
+	}
+	if idx := m.scroll.first + y; idx < m.NumberOfNodes() {
+		return idx
+	}
+	return -1
+}
+
+// updateMouse handles mouse events, with X and Y relative to the top left of the view.
+func (m *Model) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	msg.Y -= m.scroll.top
+	if m.inDetail(msg.X, msg.Y) {
+		// the wheel scrolls the detail pane under the pointer, and clicks do nothing
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.ScrollDetail(-wheelRows)
+		case tea.MouseButtonWheelDown:
+			m.ScrollDetail(wheelRows)
+		}
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.ScrollView(-wheelRows)
+		return nil
+	case tea.MouseButtonWheelDown:
+		m.ScrollView(wheelRows)
+		return nil
+	case tea.MouseButtonLeft:
+	default:
+		return nil
+	}
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	idx := m.rowAt(msg.Y)
+	if idx < 0 {
+		return nil
+	}
+	r := m.visibleRows()[idx]
+	node := r.node
+
+	// clicking the lines before a node folds it, leaving the selection where it is
+	if msg.X < m.gutterWidth()+ansi.StringWidth(m.guide(node, r.depth)) {
+		if len(node.Children) > 0 {
+			m.SetExpand(node, !node.Expand)
+		}
+		return nil
+	}
+
+	// keep the view still, so that the rows do not move under the pointer
+	m.scroll.active, m.scroll.offset = true, m.scroll.first
+	now := time.Now()
+	double := m.scroll.lastClick == node && now.Sub(m.scroll.lastClickTime) <= doubleClickInterval
+	m.scroll.lastClick, m.scroll.lastClickTime = node, now
+	selected := m.currentNode == node
+	m.selectNode(node)
+	switch {
+	case double:
+		m.ExpandSubtree()
+		// a third click starts a new double click
+		m.scroll.lastClick = nil
+	case selected && len(node.Children) > 0:
+		// clicking the selected node folds it, as top level nodes have no lines to click
+		m.SetExpand(node, !node.Expand)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func click(m *Model, x, y int) {
+	m.Update(tea.MouseMsg{X: x, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+}
+
+func TestMouseClick(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.View()
+
+	// rows start below the breadcrumb
+	click(m, 4, 2)
+	assert.Equal(t, "location", m.SelectedNode().Value)
+	assert.False(t, m.nodes[1].Expand)
+
+	// a second click on the same node expands everything below it
+	click(m, 4, 2)
+	assert.Equal(t, 8, m.NumberOfNodes())
+	assert.True(t, m.nodes[1].Children[2].Expand)
+
+	// clicking the guide of coordinates folds it without selecting it
+	m.View()
+	click(m, 1, 5)
+	assert.False(t, m.nodes[1].Children[2].Expand)
+	assert.Equal(t, "location", m.SelectedNode().Value)
+
+	click(m, 4, 21)
+	assert.Equal(t, "location", m.SelectedNode().Value)
+
+	// once the double click has passed, clicking the selected top level node folds it
+	m.scroll.lastClickTime = m.scroll.lastClickTime.Add(-time.Second)
+	m.View()
+	click(m, 4, 2)
+	assert.False(t, m.nodes[1].Expand)
+	assert.Equal(t, 3, m.NumberOfNodes())
+	m.scroll.lastClickTime = m.scroll.lastClickTime.Add(-time.Second)
+	click(m, 4, 2)
+	assert.True(t, m.nodes[1].Expand)
+}
+
+func TestMouseWheel(t *testing.T) {
+	m := New(largeTree(10, 10), 80, 5)
+	m.ExpandAll()
+	m.renderTree(5)
+	assert.Equal(t, 0, m.scroll.first)
+
+	m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	lines := strings.Split(ansi.Strip(m.renderTree(5)), "\n")
+	assert.Equal(t, 6, m.scroll.first)
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "├── 5")
+	assert.Equal(t, 0, m.Cursor())
+
+	// clicking selects the row under the pointer without moving the view
+	click(m, 8, 2)
+	m.renderTree(5)
+	assert.Equal(t, 8, m.Cursor())
+	assert.Equal(t, 6, m.scroll.first)
+
+	m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	m.renderTree(5)
+	assert.Equal(t, 0, m.scroll.first)
+
+	// pressing a key returns the view to the cursor
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.renderTree(5)
+	assert.Equal(t, 7, m.scroll.first)
+}
@@ -0,0 +1,91 @@
+package tree
+
+// MoveCursor moves the cursor by delta rows, stopping at the first and last rows.
+func (m *Model) MoveCursor(delta int) {
+	m.cursor += delta
+	if n := m.NumberOfNodes(); m.cursor >= n {
+		m.cursor = n - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.currentNode = m.nodeAt(m.cursor)
+}
+
+// NavTop moves the cursor to the first row.
+func (m *Model) NavTop() {
+	m.MoveCursor(-m.cursor)
+}
+
+// NavBottom moves the cursor to the last row.
+func (m *Model) NavBottom() {
+	m.MoveCursor(m.NumberOfNodes() - 1 - m.cursor)
+}
+
+// pageSize returns the number of rows moved by a page up or down.
+func (m *Model) pageSize() int {
+	return max(m.height, 1)
+}
+
+// PageUp moves the cursor up by a page.
+func (m *Model) PageUp() {
+	m.MoveCursor(-m.pageSize())
+}
+
+// PageDown moves the cursor down by a page.
+func (m *Model) PageDown() {
+	m.MoveCursor(m.pageSize())
+}
+
+// HalfPageUp moves the cursor up by half a page.
+func (m *Model) HalfPageUp() {
+	m.MoveCursor(-max(m.pageSize()/2, 1))
+}
+
+// HalfPageDown moves the cursor down by half a page.
+func (m *Model) HalfPageDown() {
+	m.MoveCursor(max(m.pageSize()/2, 1))
+}
+
+// NextSibling moves the cursor to the next sibling of the selected node, if it has one.
+func (m *Model) NextSibling() {
+	m.moveToSibling(1)
+}
+
+// PrevSibling moves the cursor to the previous sibling of the selected node, if it has one.
+func (m *Model) PrevSibling() {
+	m.moveToSibling(-1)
+}
+
+// NavParent moves the cursor to the parent of the selected node.
+func (m *Model) NavParent() {
+	node := m.currentNode
+	if node != nil && node.Parent != nil && node != m.zoom.root {
+		m.selectNode(node.Parent)
+	}
+}
+
+// moveToSibling moves the cursor to the closest shown sibling in direction step.
+func (m *Model) moveToSibling(step int) {
+	node := m.currentNode
+	// the zoomed node is shown without its siblings
+	if node == nil || node == m.zoom.root {
+		return
+	}
+	siblings := m.nodes
+	if node.Parent != nil {
+		siblings = node.Parent.Children
+	}
+	for i, sibling := range siblings {
+		if sibling != node {
+			continue
+		}
+		for j := i + step; j >= 0 && j < len(siblings); j += step {
+			if m.shown(siblings[j]) {
+				m.selectNode(siblings[j])
+				return
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package tree
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNavigatePages(t *testing.T) {
+	m := New(testNodes(), 80, 4)
+	m.SetExpand(m.nodes[1], true)
+	m.SetExpand(m.nodes[1].Children[2], true)
+	// ip, location, continent, country, coordinates, latitude, longitude, asn
+	assert.Equal(t, 8, m.NumberOfNodes())
+
+	m.NavBottom()
+	assert.Equal(t, 7, m.Cursor())
+	assert.Equal(t, "asn", m.currentNode.Value)
+	m.PageUp()
+	assert.Equal(t, 3, m.Cursor())
+	m.HalfPageUp()
+	assert.Equal(t, 1, m.Cursor())
+	m.HalfPageUp()
+	assert.Equal(t, 0, m.Cursor())
+	m.HalfPageDown()
+	assert.Equal(t, 2, m.Cursor())
+	m.PageDown()
+	m.PageDown()
+	assert.Equal(t, 7, m.Cursor())
+	m.NavTop()
+	assert.Equal(t, "ip", m.currentNode.Value)
+}
+
+func TestNavigateSections(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetExpand(m.nodes[1], true)
+
+	m.NextSibling()
+	assert.Equal(t, "location", m.currentNode.Value)
+	m.NextSibling()
+	assert.Equal(t, "asn", m.currentNode.Value)
+	m.NextSibling()
+	assert.Equal(t, "asn", m.currentNode.Value)
+	m.PrevSibling()
+	m.MoveCursor(1)
+	assert.Equal(t, "continent", m.currentNode.Value)
+	m.PrevSibling()
+	assert.Equal(t, "continent", m.currentNode.Value)
+	m.NextSibling()
+	m.NextSibling()
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+	m.NavParent()
+	assert.Equal(t, "location", m.currentNode.Value)
+	m.NavParent()
+	assert.Equal(t, "location", m.currentNode.Value)
+
+	m.SetFilter("ia")
+	m.MoveCursor(1)
+	assert.Equal(t, "continent", m.currentNode.Value)
+	m.NextSibling()
+	assert.Equal(t, "country", m.currentNode.Value)
+	// coordinates is hidden by the filter, so country has no next sibling
+	m.NextSibling()
+	assert.Equal(t, "country", m.currentNode.Value)
+}
+
+func TestNavigateKeys(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	assert.Equal(t, 2, m.Cursor())
+	m.Update(tea.KeyMsg{Type: tea.KeyHome})
+	assert.Equal(t, 0, m.Cursor())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	assert.Equal(t, "location", m.currentNode.Value)
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.Equal(t, 2, m.Cursor())
+}
@@ -0,0 +1,169 @@
+package tree
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PathFormat is a syntax for writing the path to a node.
+type PathFormat int
+
+const (
+	// PathJSONPath formats paths as JSONPath, e.g. $.location.coordinates.latitude
+	PathJSONPath PathFormat = iota
+	// PathJSONPointer formats paths as an RFC 6901 JSON Pointer, e.g. /location/coordinates/latitude
+	PathJSONPointer
+	// PathJq formats paths as a jq filter, e.g. .location.coordinates.latitude
+	PathJq
+	// PathGoTemplate formats paths as a Go template action, e.g. {{ .location.coordinates.latitude }}
+	PathGoTemplate
+)
+
+var pathFormatNames = map[PathFormat]string{
+	PathJSONPath:    "jsonpath",
+	PathJSONPointer: "pointer",
+	PathJq:          "jq",
+	PathGoTemplate:  "template",
+}
+
+// identifier matches keys which may be written without quoting.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (f PathFormat) String() string {
+	if name, ok := pathFormatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("PathFormat(%d)", int(f))
+}
+
+// ParsePathFormat returns the format with the given name.
+func ParsePathFormat(name string) (PathFormat, error) {
+	for format, formatName := range pathFormatNames {
+		if strings.EqualFold(name, formatName) {
+			return format, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown path format %q, expected one of jsonpath, pointer, jq or template", name)
+}
+
+// Next returns the format following f, wrapping back to the first.
+func (f PathFormat) Next() PathFormat {
+	return (f + 1) % PathFormat(len(pathFormatNames))
+}
+
+// Path returns the node and its ancestors, starting from the top level node.
+func (n *Node) Path() []*Node {
+	path := make([]*Node, 0)
+	for node := n; node != nil; node = node.Parent {
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// findPath returns the node in nodes reached by following the values of path,
+// or nil if there is none.
+func findPath(nodes []*Node, path []*Node) *Node {
+	var found *Node
+	for _, step := range path {
+		found = nil
+		for _, node := range nodes {
+			if node.Value == step.Value {
+				found = node
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		nodes = found.Children
+	}
+	return found
+}
+
+// FormatPath returns the path to the node in the given syntax. Synthetic nodes
+// are left out, so the path of a node beneath an NDJSON line or YAML document
+// is relative to that line or document, and the path of a node in the results
+// of a query is relative to the result.
+func (n *Node) FormatPath(format PathFormat) string {
+	path := make([]*Node, 0)
+	for _, node := range n.Path() {
+		if !node.Synthetic {
+			path = append(path, node)
+		}
+	}
+	var b strings.Builder
+	switch format {
+	case PathJSONPointer:
+		for _, node := range path {
+			b.WriteString("/")
+			b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(node.Value))
+		}
+	case PathJq:
+		if len(path) == 0 {
+			return "."
+		}
+		for i, node := range path {
+			switch {
+			case identifier.MatchString(node.Value) && !node.InArray:
+				b.WriteString("." + node.Value)
+				continue
+			case i == 0:
+				// jq requires a dot before a leading index
+				b.WriteString(".")
+			}
+			if node.InArray {
+				b.WriteString("[" + node.Value + "]")
+			} else {
+				b.WriteString("[" + strconv.Quote(node.Value) + "]")
+			}
+		}
+	case PathGoTemplate:
+		if len(path) == 0 {
+			return "{{ . }}"
+		}
+		simple := true
+		args := make([]string, 0, len(path))
+		for _, node := range path {
+			if node.InArray {
+				simple = false
+				args = append(args, node.Value)
+			} else {
+				simple = simple && identifier.MatchString(node.Value)
+				args = append(args, strconv.Quote(node.Value))
+			}
+		}
+		if !simple {
+			return "{{ index . " + strings.Join(args, " ") + " }}"
+		}
+		for _, node := range path {
+			b.WriteString("." + node.Value)
+		}
+		return "{{ " + b.String() + " }}"
+	default:
+		b.WriteString("$")
+		for _, node := range path {
+			switch {
+			case node.InArray:
+				b.WriteString("[" + node.Value + "]")
+			case identifier.MatchString(node.Value):
+				b.WriteString("." + node.Value)
+			default:
+				b.WriteString("['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(node.Value) + "']")
+			}
+		}
+	}
+	return b.String()
+}
+
+// linkParents sets the parent of every node beneath nodes.
+func linkParents(nodes []*Node, parent *Node) {
+	for _, node := range nodes {
+		node.Parent = parent
+		linkParents(node.Children, node)
+	}
+}
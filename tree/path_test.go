@@ -0,0 +1,72 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPath(t *testing.T) {
+	nodes := []*Node{
+		{Value: "location", Children: []*Node{
+			{Value: "coordinates", Children: []*Node{
+				{Value: "latitude"},
+			}},
+		}},
+		{Value: "cidrs", Children: []*Node{
+			{Value: "0", InArray: true, Children: []*Node{
+				{Value: "a/b~c"},
+			}},
+		}},
+		{Value: "1", InArray: true},
+		{Value: "2", Synthetic: true, Children: []*Node{
+			{Value: "a"},
+		}},
+	}
+	New(nodes, 80, 24)
+	latitude := nodes[0].Children[0].Children[0]
+	escaped := nodes[1].Children[0].Children[0]
+	index := nodes[2]
+	// synthetic nodes, such as NDJSON lines, are left out of paths
+	line, field := nodes[3], nodes[3].Children[0]
+
+	tests := []struct {
+		name   string
+		node   *Node
+		format PathFormat
+		want   string
+	}{
+		{"jsonpath", latitude, PathJSONPath, "$.location.coordinates.latitude"},
+		{"jsonpath escaped", escaped, PathJSONPath, "$.cidrs[0]['a/b~c']"},
+		{"jsonpath index", index, PathJSONPath, "$[1]"},
+		{"pointer", latitude, PathJSONPointer, "/location/coordinates/latitude"},
+		{"pointer escaped", escaped, PathJSONPointer, "/cidrs/0/a~1b~0c"},
+		{"jq", latitude, PathJq, ".location.coordinates.latitude"},
+		{"jq escaped", escaped, PathJq, `.cidrs[0]["a/b~c"]`},
+		{"jq index", index, PathJq, ".[1]"},
+		{"template", latitude, PathGoTemplate, "{{ .location.coordinates.latitude }}"},
+		{"template escaped", escaped, PathGoTemplate, `{{ index . "cidrs" 0 "a/b~c" }}`},
+		{"jsonpath synthetic", field, PathJSONPath, "$.a"},
+		{"jsonpath synthetic root", line, PathJSONPath, "$"},
+		{"pointer synthetic root", line, PathJSONPointer, ""},
+		{"jq synthetic", field, PathJq, ".a"},
+		{"jq synthetic root", line, PathJq, "."},
+		{"template synthetic root", line, PathGoTemplate, "{{ . }}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.node.FormatPath(tt.format))
+		})
+	}
+}
+
+func TestParsePathFormat(t *testing.T) {
+	for format := PathJSONPath; format <= PathGoTemplate; format++ {
+		parsed, err := ParsePathFormat(format.String())
+		assert.NoError(t, err)
+		assert.Equal(t, format, parsed)
+	}
+	assert.Equal(t, PathJSONPath, PathGoTemplate.Next())
+	_, err := ParsePathFormat("xpath")
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package tree
+
+import "strconv"
+
+// pickMarker is drawn before each picked node
+const pickMarker = "●"
+
+// pick holds the nodes marked while picking several nodes.
+type pick struct {
+	picked map[*Node]bool
+}
+
+// SetMultiPick allows several nodes to be picked, drawing a marker before
+// each picked node.
+func (m *Model) SetMultiPick(multi bool) {
+	m.MultiPick = multi
+	if !multi {
+		m.pick.picked = nil
+	}
+}
+
+// IsPicked returns true if node has been picked.
+func (m *Model) IsPicked(node *Node) bool {
+	return m.pick.picked[node]
+}
+
+// TogglePick picks the selected node, or unpicks it if it was picked, and
+// moves the cursor down to the next node.
+func (m *Model) TogglePick() {
+	node := m.SelectedNode()
+	if node == nil {
+		return
+	}
+	if m.pick.picked == nil {
+		m.pick.picked = make(map[*Node]bool)
+	}
+	if m.pick.picked[node] {
+		delete(m.pick.picked, node)
+	} else {
+		m.pick.picked[node] = true
+	}
+	m.NavDown()
+}
+
+// Picked returns the picked nodes in the tree, in document order, regardless of
+// whether they are hidden by a collapsed parent, filter or zoom.
+func (m *Model) Picked() []*Node {
+	if len(m.pick.picked) == 0 {
+		return nil
+	}
+	picked := make([]*Node, 0, len(m.pick.picked))
+	walkNodes(m.nodes, nil, func(node *Node, _ []*Node) {
+		if m.pick.picked[node] {
+			picked = append(picked, node)
+		}
+	})
+	return picked
+}
+
+// pickGutter returns the marker drawn before node if it is picked.
+func (m *Model) pickGutter(node *Node) string {
+	if !m.pick.picked[node] {
+		return pad("", gutterWidth)
+	}
+	return m.Styles.Picked.Render(pad(pickMarker, gutterWidth))
+}
+
+// pickSummary describes the number of picked nodes.
+func (m *Model) pickSummary() string {
+	return m.Styles.Picked.Render(strconv.Itoa(len(m.Picked())) + " picked")
+}
@@ -0,0 +1,50 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPick(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+	// space does nothing unless several nodes may be picked
+	m.Update(space)
+	assert.Nil(t, m.Picked())
+	assert.Equal(t, "ip", m.SelectedNode().Value)
+
+	m.SetMultiPick(true)
+	m.SetExpand(m.nodes[1], true)
+	m.SelectNode(m.nodes[2])
+	m.Update(space)
+	m.SelectNode(m.nodes[1].Children[1])
+	m.Update(space)
+	// picking moves the cursor down
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+	assert.Equal(t, []*Node{m.nodes[1].Children[1], m.nodes[2]}, m.Picked())
+
+	lines := strings.Split(ansi.Strip(m.renderTree(m.Height())), "\n")
+	assert.Equal(t, []string{
+		"  ip               1.1.1.1",
+		"  location         Oceania Australia",
+		"  ├── continent    Oceania",
+		"● ├── country      Australia",
+		"  └── coordinates  -27.482 153.018",
+		"● asn              13335",
+	}, lines)
+	assert.Equal(t, "2 picked", ansi.Strip(m.pickSummary()))
+
+	// picked nodes stay picked while hidden
+	m.SetExpand(m.nodes[1], false)
+	assert.Len(t, m.Picked(), 2)
+	m.SelectNode(m.nodes[2])
+	m.TogglePick()
+	assert.Equal(t, []*Node{m.nodes[1].Children[1]}, m.Picked())
+
+	m.SetMultiPick(false)
+	assert.Nil(t, m.Picked())
+}
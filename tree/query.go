@@ -0,0 +1,87 @@
+package tree
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type queryPrompt struct {
+	input textinput.Model
+	query string
+	err   error
+}
+
+func newQueryPrompt() queryPrompt {
+	input := textinput.New()
+	input.Prompt = ":"
+	return queryPrompt{input: input}
+}
+
+// Querying returns true while the query prompt is capturing key presses.
+func (m *Model) Querying() bool {
+	return m.queryPrompt.input.Focused()
+}
+
+// QueryString returns the query the displayed nodes were produced by.
+func (m *Model) QueryString() string {
+	return m.queryPrompt.query
+}
+
+// StartQuery opens the query prompt. It does nothing if the model has no Query function.
+func (m *Model) StartQuery() tea.Cmd {
+	if m.Query == nil {
+		return nil
+	}
+	m.queryPrompt.input.SetValue(m.queryPrompt.query)
+	m.queryPrompt.input.CursorEnd()
+	return m.queryPrompt.input.Focus()
+}
+
+// RunQuery replaces the displayed nodes with the result of running query.
+func (m *Model) RunQuery(query string) error {
+	if m.Query == nil {
+		return nil
+	}
+	nodes, err := m.Query(query)
+	m.queryPrompt.err = err
+	if err != nil {
+		return err
+	}
+	m.queryPrompt.query = query
+	m.SetNodes(nodes)
+	return nil
+}
+
+// updateQuery handles key presses while the query prompt is open.
+func (m *Model) updateQuery(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.KeyMap.AcceptQuery):
+		if err := m.RunQuery(m.queryPrompt.input.Value()); err == nil {
+			m.queryPrompt.input.Blur()
+		}
+		return nil
+	case key.Matches(msg, m.KeyMap.CancelQuery):
+		m.queryPrompt.err = nil
+		m.queryPrompt.input.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.queryPrompt.input, cmd = m.queryPrompt.input.Update(msg)
+	return cmd
+}
+
+// queryView renders the query prompt, the active query or the last query error.
+func (m *Model) queryView() string {
+	var view string
+	switch {
+	case m.Querying():
+		view = m.queryPrompt.input.View()
+	case m.queryPrompt.query != "":
+		view = m.Styles.Help.Render(m.queryPrompt.input.Prompt + m.queryPrompt.query)
+	}
+	if m.queryPrompt.err != nil {
+		view += m.Styles.Help.Render("  " + m.queryPrompt.err.Error())
+	}
+	return view
+}
@@ -0,0 +1,34 @@
+package tree
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunQuery(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	assert.Nil(t, m.StartQuery())
+
+	m.Query = func(query string) ([]*Node, error) {
+		if query == ".location" {
+			return testNodes()[1].Children, nil
+		}
+		return nil, errors.New("bad query")
+	}
+	m.SetFilter("o")
+	m.SetCursor(3)
+
+	assert.NoError(t, m.RunQuery(".location"))
+	assert.Equal(t, ".location", m.QueryString())
+	assert.Equal(t, "continent", m.nodeAt(0).Value)
+	assert.Equal(t, 0, m.Cursor())
+	assert.True(t, m.Filtered())
+	// continent, country, coordinates and longitude
+	assert.Equal(t, 4, m.NumberOfNodes())
+
+	assert.Error(t, m.RunQuery(".bad"))
+	assert.Equal(t, ".location", m.QueryString())
+	assert.Contains(t, m.queryView(), "bad query")
+}
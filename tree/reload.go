@@ -0,0 +1,103 @@
+package tree
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// flashDuration is how long the nodes changed by a reload are highlighted
+const flashDuration = 1500 * time.Millisecond
+
+// flash highlights the nodes changed by the last reload.
+type flash struct {
+	// nodes changed value or were added, and inside holds their ancestors, which
+	// are highlighted in their place while collapsed
+	nodes  map[*Node]bool
+	inside map[*Node]bool
+	// generation counts the reloads, so that only the latest flash is cleared
+	generation int
+}
+
+// flashDoneMsg ends the highlight of the reload with the same generation.
+type flashDoneMsg struct {
+	generation int
+}
+
+// ReloadNodes replaces the nodes with a new version of the same document. The
+// expand state, selection and zoom are kept for nodes at the same paths, and
+// the nodes whose values changed are highlighted briefly. It returns the
+// command which ends the highlight.
+func (m *Model) ReloadNodes(nodes []*Node) tea.Cmd {
+	expanded := make(map[string]bool)
+	descs := make(map[string]string)
+	keys := make(map[*Node]string)
+	walkNodes(m.nodes, nil, func(node *Node, _ []*Node) {
+		k := pathKey(keys, node)
+		expand := node.Expand
+		if m.Filtered() {
+			// the filter expands nodes which should stay as they were before it
+			expand = m.filter.expanded[node]
+		}
+		expanded[k], descs[k] = expand, node.Desc
+	})
+
+	linkParents(nodes, nil)
+	changed := make(map[*Node]bool)
+	added := make(map[*Node]bool)
+	clear(keys)
+	walkNodes(nodes, nil, func(node *Node, _ []*Node) {
+		k := pathKey(keys, node)
+		desc, existed := descs[k]
+		switch {
+		case !existed:
+			added[node] = true
+			// only the top of an added subtree is highlighted
+			if node.Parent == nil || !added[node.Parent] {
+				changed[node] = true
+			}
+		case len(node.Children) == 0 && desc != node.Desc:
+			changed[node] = true
+		}
+		if expand, ok := expanded[k]; ok {
+			node.Expand = expand
+		}
+	})
+
+	// everything is new on the first load, which is not worth highlighting
+	first := len(m.nodes) == 0
+	m.SetNodes(nodes)
+	m.flash = flash{generation: m.flash.generation + 1}
+	if first || len(changed) == 0 {
+		return nil
+	}
+	m.flash.nodes = changed
+	m.flash.inside = make(map[*Node]bool)
+	for node := range changed {
+		for ancestor := node.Parent; ancestor != nil && !m.flash.inside[ancestor]; ancestor = ancestor.Parent {
+			m.flash.inside[ancestor] = true
+		}
+	}
+	generation := m.flash.generation
+	return tea.Tick(flashDuration, func(time.Time) tea.Msg {
+		return flashDoneMsg{generation: generation}
+	})
+}
+
+// pathKey returns a key for the path to node, which is the same for nodes at
+// the same path in different versions of a document. Keys are memoised in keys,
+// which must hold the key of the parent of node.
+func pathKey(keys map[*Node]string, node *Node) string {
+	k := node.Value
+	if node.Parent != nil {
+		k = keys[node.Parent] + "\x00" + k
+	}
+	keys[node] = k
+	return k
+}
+
+// flashed returns true if node is highlighted after a reload, either because it
+// changed or because it is collapsed with a changed descendant.
+func (m *Model) flashed(node *Node) bool {
+	return m.flash.nodes[node] || (!node.Expand && m.flash.inside[node])
+}
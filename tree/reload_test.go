@@ -0,0 +1,71 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadNodes(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetExpand(m.nodes[1], true)
+	m.SetExpand(m.nodes[1].Children[2], true)
+	m.SelectNode(m.nodes[1].Children[2].Children[1])
+
+	nodes := testNodes()
+	nodes[1].Children[2].Children[1].Desc = "153.5"
+	nodes[1].Children[0].Desc = "Oceania"
+	nodes = append(nodes, &Node{Value: "org", Desc: "x", Children: []*Node{{Value: "name", Desc: "x"}}})
+	cmd := m.ReloadNodes(nodes)
+	assert.NotNil(t, cmd)
+
+	// the expand state and selection follow the paths into the new nodes
+	assert.Same(t, nodes[1].Children[2].Children[1], m.SelectedNode())
+	assert.True(t, nodes[1].Expand)
+	assert.True(t, nodes[1].Children[2].Expand)
+	assert.False(t, nodes[3].Expand)
+
+	// changed values and the top of added subtrees are highlighted
+	assert.True(t, m.flashed(nodes[1].Children[2].Children[1]))
+	assert.True(t, m.flashed(nodes[3]))
+	assert.False(t, m.flashed(nodes[3].Children[0]))
+	assert.False(t, m.flashed(nodes[1].Children[0]))
+	assert.False(t, m.flashed(nodes[1]))
+	m.SetExpand(nodes[1], false)
+	assert.True(t, m.flashed(nodes[1]))
+
+	// only the latest reload clears the highlight
+	assert.NotNil(t, m.ReloadNodes(testNodes()))
+	m.Update(flashDoneMsg{generation: 1})
+	assert.True(t, m.flashed(m.nodes[1].Children[2].Children[1]))
+	m.Update(flashDoneMsg{generation: 2})
+	assert.False(t, m.flashed(m.nodes[1].Children[2].Children[1]))
+	assert.Nil(t, m.ReloadNodes(testNodes()))
+}
+
+func TestReloadNodesFilteredZoomed(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SelectNode(m.nodes[1].Children[2])
+	m.ZoomIn()
+	m.ZoomOut()
+	m.SetExpand(m.nodes[1].Children[2], false)
+	m.SelectNode(m.nodes[1].Children[2])
+	m.ZoomIn()
+	m.SetFilter("lat")
+
+	nodes := testNodes()
+	m.ReloadNodes(nodes)
+	assert.Equal(t, nodes[1].Children[2], m.ZoomRoot())
+	assert.Equal(t, []string{"0:coordinates", "1:latitude"}, rowValues(m))
+	m.ClearFilter()
+	m.ZoomOut()
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+}
+
+func TestReloadNodesFirstLoad(t *testing.T) {
+	m := New(nil, 80, 24)
+	// every node is new, which is not highlighted
+	assert.Nil(t, m.ReloadNodes(testNodes()))
+	assert.False(t, m.flashed(m.nodes[0]))
+	assert.Len(t, m.nodes, 3)
+}
@@ -0,0 +1,96 @@
+package tree
+
+import "slices"
+
+// row is a visible node and its depth below the top level.
+type row struct {
+	node  *Node
+	depth int
+}
+
+// rowCache holds the visible nodes in display order, so that rows can be found
+// and rendered without walking the whole tree.
+type rowCache struct {
+	rows  []row
+	valid bool
+}
+
+// visibleRows returns the visible nodes in display order, rebuilding them if
+// they have been invalidated.
+func (m *Model) visibleRows() []row {
+	if !m.rows.valid {
+		m.rows.rows = m.appendRows(m.rows.rows[:0], m.roots(), 0)
+		m.rows.valid = true
+	}
+	return m.rows.rows
+}
+
+// appendRows appends the shown nodes and the shown descendants of expanded nodes to rows.
+func (m *Model) appendRows(rows []row, nodes []*Node, depth int) []row {
+	for _, node := range nodes {
+		if !m.shown(node) {
+			continue
+		}
+		rows = append(rows, row{node: node, depth: depth})
+		if node.Children != nil && node.Expand {
+			rows = m.appendRows(rows, node.Children, depth+1)
+		}
+	}
+	return rows
+}
+
+// invalidateRows marks the visible rows as needing to be rebuilt.
+func (m *Model) invalidateRows() {
+	m.rows.valid = false
+}
+
+// Refresh rebuilds the visible rows. It must be called after changing the
+// Expand or Children fields of nodes directly rather than through the model.
+func (m *Model) Refresh() {
+	m.invalidateRows()
+	m.syncSelection()
+}
+
+// SetExpand expands or collapses node, updating the visible rows in place
+// rather than rebuilding them.
+func (m *Model) SetExpand(node *Node, expand bool) {
+	if node.Expand == expand {
+		return
+	}
+	node.Expand = expand
+	if !m.rows.valid {
+		return
+	}
+	idx := m.indexOf(node)
+	if idx < 0 {
+		// the node is hidden, so its children are hidden either way
+		return
+	}
+	rows := m.rows.rows
+	depth := rows[idx].depth
+	if expand {
+		m.rows.rows = slices.Insert(rows, idx+1, m.appendRows(nil, node.Children, depth+1)...)
+	} else {
+		end := idx + 1
+		for end < len(rows) && rows[end].depth > depth {
+			end++
+		}
+		m.rows.rows = slices.Delete(rows, idx+1, end)
+	}
+	// rows above the cursor may have been added or removed
+	m.selectNode(m.currentNode)
+}
+
+// expandAncestors expands every ancestor of node so that it is visible.
+func (m *Model) expandAncestors(node *Node) {
+	collapsed := false
+	for ancestor := node.Parent; ancestor != nil; ancestor = ancestor.Parent {
+		if !ancestor.Expand {
+			ancestor.Expand = true
+			collapsed = true
+		}
+	}
+	if collapsed {
+		m.invalidateRows()
+	}
+}
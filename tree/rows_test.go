@@ -0,0 +1,113 @@
+package tree
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rowValues returns the values of the visible nodes in display order.
+func rowValues(m *Model) []string {
+	values := make([]string, 0)
+	for _, r := range m.visibleRows() {
+		values = append(values, strconv.Itoa(r.depth)+":"+r.node.Value)
+	}
+	return values
+}
+
+func TestSetExpandRows(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetCursor(2)
+	m.SetExpand(m.nodes[1], true)
+	assert.Equal(t, []string{"0:ip", "0:location", "1:continent", "1:country", "1:coordinates", "0:asn"}, rowValues(m))
+	assert.Equal(t, 5, m.Cursor())
+
+	m.SetExpand(m.nodes[1].Children[2], true)
+	assert.Equal(t, []string{"0:ip", "0:location", "1:continent", "1:country", "1:coordinates", "2:latitude", "2:longitude", "0:asn"}, rowValues(m))
+
+	m.SetExpand(m.nodes[1], false)
+	assert.Equal(t, []string{"0:ip", "0:location", "0:asn"}, rowValues(m))
+	assert.Equal(t, 2, m.Cursor())
+
+	// the grandchildren stay expanded, so reappear along with their parent
+	m.SetExpand(m.nodes[1], true)
+	m.Refresh()
+	assert.Equal(t, 8, m.NumberOfNodes())
+}
+
+func TestRenderOnlyDisplayRange(t *testing.T) {
+	m := New(largeTree(10, 10), 80, 5)
+	m.ExpandAll()
+	m.SetCursor(50)
+	// row 50 is the sixth child of the fifth parent, so only its neighbouring children are rendered
+	lines := strings.Split(strings.TrimSuffix(m.renderTree(m.Height()), "\n"), "\n")
+	assert.Len(t, lines, 5)
+	assert.Contains(t, lines[0], "3")
+	assert.Contains(t, lines[4], "7")
+	for _, line := range lines {
+		assert.Contains(t, line, "child")
+	}
+}
+
+// largeTree creates width top level nodes, each with width children of their own.
+func largeTree(width int, children int) []*Node {
+	nodes := make([]*Node, 0, width)
+	for i := 0; i < width; i++ {
+		node := &Node{Value: strconv.Itoa(i), Desc: "parent", Children: make([]*Node, 0, children)}
+		for j := 0; j < children; j++ {
+			node.Children = append(node.Children, &Node{Value: strconv.Itoa(j), Desc: "child"})
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// benchmarkModel creates a fully expanded tree of about a million nodes.
+func benchmarkModel(b *testing.B) *Model {
+	b.Helper()
+	m := New(largeTree(1000, 1000), 80, 40)
+	m.ExpandAll()
+	m.SetCursor(m.NumberOfNodes() / 2)
+	return m
+}
+
+func BenchmarkView(b *testing.B) {
+	m := benchmarkModel(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.View()
+	}
+}
+
+func BenchmarkNavDown(b *testing.B) {
+	m := benchmarkModel(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.NavDown()
+		m.View()
+	}
+}
+
+func BenchmarkPageDown(b *testing.B) {
+	m := benchmarkModel(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if m.Cursor() == m.NumberOfNodes()-1 {
+			m.NavTop()
+		}
+		m.PageDown()
+		m.View()
+	}
+}
+
+func BenchmarkToggle(b *testing.B) {
+	m := benchmarkModel(b)
+	m.NavTop()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.InvertCollaped()
+		m.View()
+	}
+}
@@ -0,0 +1,216 @@
+package tree
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type search struct {
+	input textinput.Model
+	query string
+	// matches are the nodes matching the query, in depth first order
+	matches []*Node
+	current int
+}
+
+func newSearch() search {
+	input := textinput.New()
+	input.Prompt = "/"
+	return search{input: input}
+}
+
+// Searching returns true while the search prompt is capturing key presses.
+func (m *Model) Searching() bool {
+	return m.search.input.Focused()
+}
+
+// SearchQuery returns the active search query.
+func (m *Model) SearchQuery() string {
+	return m.search.query
+}
+
+// StartSearch opens the search prompt.
+func (m *Model) StartSearch() tea.Cmd {
+	m.search.input.SetValue(m.search.query)
+	m.search.input.CursorEnd()
+	return m.search.input.Focus()
+}
+
+// SetSearch searches every node, including those in collapsed subtrees, for
+// query and moves the cursor to the first match at or after the cursor.
+func (m *Model) SetSearch(query string) {
+	m.search.query = query
+	m.findMatches()
+	if len(m.search.matches) > 0 {
+		m.selectMatch()
+	}
+}
+
+// findMatches collects the nodes matching the active search in display order,
+// setting the current match to the first at or after the cursor.
+func (m *Model) findMatches() {
+	m.search.matches = nil
+	m.search.current = 0
+	query := m.search.query
+	if query == "" {
+		return
+	}
+	start := -1
+	walkNodes(m.roots(), nil, func(node *Node, _ []*Node) {
+		if !m.shown(node) {
+			return
+		}
+		if node == m.currentNode {
+			start = len(m.search.matches)
+		}
+		// the description of a parent summarises its children, which are searched directly
+		if indexFold(node.Value, query) >= 0 || (len(node.Children) == 0 && indexFold(node.Desc, query) >= 0) {
+			m.search.matches = append(m.search.matches, node)
+		}
+	})
+	if start >= 0 && start < len(m.search.matches) {
+		m.search.current = start
+	}
+}
+
+// ClearSearch removes the active search and its highlighting.
+func (m *Model) ClearSearch() {
+	m.search.input.Blur()
+	m.search.input.Reset()
+	m.SetSearch("")
+}
+
+// NextMatch moves the cursor to the next search match, wrapping at the end.
+func (m *Model) NextMatch() {
+	if len(m.search.matches) == 0 {
+		return
+	}
+	m.search.current = (m.search.current + 1) % len(m.search.matches)
+	m.selectMatch()
+}
+
+// PrevMatch moves the cursor to the previous search match, wrapping at the start.
+func (m *Model) PrevMatch() {
+	if len(m.search.matches) == 0 {
+		return
+	}
+	m.search.current = (m.search.current - 1 + len(m.search.matches)) % len(m.search.matches)
+	m.selectMatch()
+}
+
+// selectMatch expands the ancestors of the current match and moves the cursor to it.
+func (m *Model) selectMatch() {
+	match := m.search.matches[m.search.current]
+	m.expandAncestors(match)
+	m.selectNode(match)
+}
+
+// updateSearch handles key presses while the search prompt is open.
+func (m *Model) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.KeyMap.AcceptSearch):
+		m.search.input.Blur()
+		return nil
+	case key.Matches(msg, m.KeyMap.CancelSearch):
+		m.ClearSearch()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	if query := m.search.input.Value(); query != m.search.query {
+		m.SetSearch(query)
+	}
+	return cmd
+}
+
+// searchView renders the search prompt or the match counter for the active search.
+func (m *Model) searchView() string {
+	if m.Searching() {
+		return m.search.input.View() + m.matchCounter()
+	}
+	if m.search.query == "" {
+		return ""
+	}
+	return m.Styles.Help.Render("/"+m.search.query) + m.matchCounter()
+}
+
+func (m *Model) matchCounter() string {
+	if m.search.query == "" {
+		return ""
+	}
+	if len(m.search.matches) == 0 {
+		return m.Styles.Help.Render("  no matches")
+	}
+	return m.Styles.Help.Render(fmt.Sprintf("  %d/%d", m.search.current+1, len(m.search.matches)))
+}
+
+// highlight renders s with style, rendering substrings matching the active search with the match style.
+func (m *Model) highlight(s string, style lipgloss.Style) string {
+	query := m.search.query
+	if query == "" {
+		return style.Render(s)
+	}
+	var b strings.Builder
+	for {
+		idx := indexFold(s, query)
+		if idx < 0 {
+			break
+		}
+		b.WriteString(style.Render(s[:idx]))
+		b.WriteString(m.Styles.Match.Render(s[idx : idx+len(query)]))
+		s = s[idx+len(query):]
+	}
+	if s != "" {
+		b.WriteString(style.Render(s))
+	}
+	return b.String()
+}
+
+// indexOf returns the row of target amongst the visible nodes, or -1 if it is hidden.
+func (m *Model) indexOf(target *Node) int {
+	if target == nil {
+		return -1
+	}
+	rows := m.visibleRows()
+	// the target is usually at or near the cursor, so search outwards from it
+	for d := 0; m.cursor-d >= 0 || m.cursor+d < len(rows); d++ {
+		if i := m.cursor - d; i >= 0 && i < len(rows) && rows[i].node == target {
+			return i
+		}
+		if i := m.cursor + d; i >= 0 && i < len(rows) && rows[i].node == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// walkNodes calls fn on every node in depth first order, regardless of whether it is expanded.
+func walkNodes(nodes []*Node, ancestors []*Node, fn func(node *Node, ancestors []*Node)) {
+	for _, node := range nodes {
+		fn(node, ancestors)
+		if len(node.Children) > 0 {
+			walkNodes(node.Children, append(ancestors, node), fn)
+		}
+	}
+}
+
+// indexFold returns the byte index of the first case insensitive instance of substr in s, or -1.
+func indexFold(s, substr string) int {
+	if substr == "" {
+		return -1
+	}
+	for i := 0; i+len(substr) <= len(s); {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return i
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return -1
+}
@@ -0,0 +1,70 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testNodes() []*Node {
+	return []*Node{
+		{Value: "ip", Desc: "1.1.1.1"},
+		{Value: "location", Desc: "Oceania Australia", Children: []*Node{
+			{Value: "continent", Desc: "Oceania"},
+			{Value: "country", Desc: "Australia"},
+			{Value: "coordinates", Desc: "-27.482 153.018", Children: []*Node{
+				{Value: "latitude", Desc: "-27.482"},
+				{Value: "longitude", Desc: "153.018"},
+			}},
+		}},
+		{Value: "asn", Desc: "13335"},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetSearch("LAT")
+	assert.Equal(t, "1/1", m.matchCounter()[2:])
+	assert.Equal(t, "latitude", m.currentNode.Value)
+	assert.True(t, m.nodes[1].Expand)
+	assert.True(t, m.nodes[1].Children[2].Expand)
+	assert.Equal(t, 5, m.Cursor())
+
+	m.SetSearch("australia")
+	assert.Len(t, m.search.matches, 1)
+	assert.Equal(t, "country", m.currentNode.Value)
+
+	m.SetSearch("co")
+	assert.Len(t, m.search.matches, 3)
+	assert.Equal(t, "country", m.currentNode.Value)
+	m.NextMatch()
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+	m.NextMatch()
+	assert.Equal(t, "continent", m.currentNode.Value)
+	m.PrevMatch()
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+
+	m.SetSearch("missing")
+	assert.Empty(t, m.search.matches)
+	assert.Equal(t, "coordinates", m.currentNode.Value)
+
+	m.ClearSearch()
+	assert.Equal(t, "", m.SearchQuery())
+	assert.Equal(t, "", m.matchCounter())
+}
+
+func TestIndexFold(t *testing.T) {
+	tests := []struct {
+		s, substr string
+		want      int
+	}{
+		{"Brisbane", "bris", 0},
+		{"Brisbane", "BANE", 4},
+		{"Brisbane", "x", -1},
+		{"Brisbane", "", -1},
+		{"Zürich", "RICH", 3},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, indexFold(tt.s, tt.substr), tt.s+"/"+tt.substr)
+	}
+}
@@ -0,0 +1,176 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortMode is an order for the children of each node.
+type SortMode int
+
+const (
+	// SortDocument keeps nodes in the order they appear in the source document
+	SortDocument SortMode = iota
+	// SortKey orders nodes alphabetically by key, and arrays by index
+	SortKey
+	// SortType orders nodes by the type of their value: null, boolean, number, string, array then object
+	SortType
+	// SortSize orders nodes by the number of descendants they have, largest first
+	SortSize
+)
+
+var sortModeNames = []string{"document", "key", "type", "size"}
+
+func (s SortMode) String() string {
+	if int(s) < len(sortModeNames) {
+		return sortModeNames[s]
+	}
+	return fmt.Sprintf("SortMode(%d)", int(s))
+}
+
+type sorter struct {
+	mode SortMode
+	// original holds the children of each node in document order, with the top
+	// level nodes stored under nil
+	original map[*Node][]*Node
+}
+
+// SortMode returns the order nodes are displayed in.
+func (m *Model) SortMode() SortMode {
+	return m.sort.mode
+}
+
+// SetSortMode reorders the children of every node, keeping the cursor on the selected node.
+func (m *Model) SetSortMode(mode SortMode) {
+	if m.sort.original == nil {
+		m.sort.original = map[*Node][]*Node{nil: append([]*Node(nil), m.nodes...)}
+		walkNodes(m.nodes, nil, func(node *Node, _ []*Node) {
+			if len(node.Children) > 0 {
+				m.sort.original[node] = append([]*Node(nil), node.Children...)
+			}
+		})
+	}
+	m.sort.mode = mode
+	less := m.sortLess()
+	m.sortNodes(nil, m.nodes, less)
+	walkNodes(m.nodes, nil, func(node *Node, _ []*Node) {
+		if len(node.Children) > 0 {
+			m.sortNodes(node, node.Children, less)
+		}
+	})
+	m.invalidateRows()
+	m.selectNode(m.currentNode)
+	m.findMatches()
+}
+
+// restoreOrder returns the nodes to document order in place and forgets it, as
+// the slices may be shared with the caller, which could give them back later.
+func (m *Model) restoreOrder() {
+	for parent, original := range m.sort.original {
+		nodes := m.nodes
+		if parent != nil {
+			nodes = parent.Children
+		}
+		if len(nodes) == len(original) {
+			copy(nodes, original)
+		}
+	}
+	m.sort.original = nil
+}
+
+// CycleSortMode switches to the next sort mode.
+func (m *Model) CycleSortMode() {
+	m.SetSortMode((m.sort.mode + 1) % SortMode(len(sortModeNames)))
+	m.status = "sort: " + m.sort.mode.String()
+}
+
+// sortNodes restores the children of parent to document order, then sorts them with less.
+func (m *Model) sortNodes(parent *Node, nodes []*Node, less func(a, b *Node) bool) {
+	if original, ok := m.sort.original[parent]; ok && len(original) == len(nodes) {
+		copy(nodes, original)
+	}
+	if less == nil {
+		return
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return less(nodes[i], nodes[j])
+	})
+}
+
+// sortLess returns the comparison for the active sort mode, or nil for document order.
+func (m *Model) sortLess() func(a, b *Node) bool {
+	switch m.sort.mode {
+	case SortKey:
+		return func(a, b *Node) bool {
+			if a.InArray && b.InArray {
+				i, _ := strconv.Atoi(a.Value)
+				j, _ := strconv.Atoi(b.Value)
+				return i < j
+			}
+			if cmp := strings.Compare(strings.ToLower(a.Value), strings.ToLower(b.Value)); cmp != 0 {
+				return cmp < 0
+			}
+			return a.Value < b.Value
+		}
+	case SortType:
+		return func(a, b *Node) bool {
+			return typeRank(a) < typeRank(b)
+		}
+	case SortSize:
+		sizes := make(map[*Node]int)
+		var count func(nodes []*Node) int
+		count = func(nodes []*Node) int {
+			total := 0
+			for _, node := range nodes {
+				sizes[node] = count(node.Children)
+				total += sizes[node] + 1
+			}
+			return total
+		}
+		count(m.nodes)
+		return func(a, b *Node) bool {
+			return sizes[a] > sizes[b]
+		}
+	}
+	return nil
+}
+
+// typeRank orders nodes by the type of their value.
+func typeRank(node *Node) int {
+	switch node.Type {
+	case TypeNull:
+		return 0
+	case TypeBoolean:
+		return 1
+	case TypeNumber:
+		return 2
+	case TypeString:
+		return 3
+	case TypeArray:
+		return 4
+	case TypeObject:
+		return 5
+	}
+	// nodes created without a type are ranked by their data instead
+	switch node.Data.(type) {
+	case nil:
+		if len(node.Children) == 0 {
+			return 0
+		}
+	case bool:
+		return 1
+	case float64, float32, int, int64, json.Number:
+		return 2
+	case string:
+		return 3
+	case []any:
+		return 4
+	}
+	if len(node.Children) > 0 && node.Children[0].InArray {
+		return 4
+	}
+	return 5
+}
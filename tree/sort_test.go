@@ -0,0 +1,87 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sortedValues(nodes []*Node) []string {
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, node.Value)
+	}
+	return values
+}
+
+func TestSortMode(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetCursor(2)
+
+	m.SetSortMode(SortKey)
+	assert.Equal(t, []string{"asn", "ip", "location"}, sortedValues(m.nodes))
+	assert.Equal(t, []string{"continent", "coordinates", "country"}, sortedValues(m.nodes[2].Children))
+	assert.Equal(t, "asn", m.currentNode.Value)
+	assert.Equal(t, 0, m.Cursor())
+
+	m.SetSortMode(SortSize)
+	assert.Equal(t, []string{"location", "ip", "asn"}, sortedValues(m.nodes))
+	assert.Equal(t, []string{"coordinates", "continent", "country"}, sortedValues(m.nodes[0].Children))
+	assert.Equal(t, 2, m.Cursor())
+
+	m.SetSortMode(SortDocument)
+	assert.Equal(t, sortedValues(testNodes()), sortedValues(m.nodes))
+	assert.Equal(t, []string{"continent", "country", "coordinates"}, sortedValues(m.nodes[1].Children))
+	assert.Equal(t, "asn", m.currentNode.Value)
+}
+
+func TestSortType(t *testing.T) {
+	m := New([]*Node{
+		{Value: "object", Children: []*Node{{Value: "a", Data: 1}}, Data: map[string]any{"a": 1}},
+		{Value: "array", Children: []*Node{{Value: "0", Data: 1, InArray: true}}, Data: []any{1}},
+		{Value: "string", Data: "x"},
+		{Value: "number", Data: 1.5},
+		{Value: "bool", Data: true},
+		{Value: "null"},
+	}, 80, 24)
+	m.CycleSortMode()
+	m.CycleSortMode()
+	assert.Equal(t, SortType, m.SortMode())
+	assert.Equal(t, "sort: type", m.status)
+	assert.Equal(t, []string{"null", "bool", "number", "string", "array", "object"}, sortedValues(m.nodes))
+}
+
+func TestSortArrayIndex(t *testing.T) {
+	children := make([]*Node, 0, 3)
+	for _, v := range []string{"10", "2", "1"} {
+		children = append(children, &Node{Value: v, InArray: true})
+	}
+	m := New([]*Node{{Value: "list", Children: children}}, 80, 24)
+	m.SetSortMode(SortKey)
+	assert.Equal(t, []string{"1", "2", "10"}, sortedValues(m.nodes[0].Children))
+}
+
+func TestSortQueryRoundTrip(t *testing.T) {
+	// the query returns the same slices as the document for an empty query, as Document.QueryNodes does
+	doc := []*Node{
+		{Value: "b"},
+		{Value: "a"},
+		{Value: "c", Children: []*Node{{Value: "z"}, {Value: "y"}}},
+	}
+	m := New(doc, 80, 24)
+	m.Query = func(query string) ([]*Node, error) {
+		if query == "" {
+			return doc, nil
+		}
+		return []*Node{{Value: "z"}, {Value: "y"}}, nil
+	}
+	m.SetSortMode(SortKey)
+	assert.NoError(t, m.RunQuery(".c"))
+	assert.Equal(t, []string{"y", "z"}, sortedValues(m.nodes))
+	assert.NoError(t, m.RunQuery(""))
+	assert.Equal(t, []string{"a", "b", "c"}, sortedValues(m.nodes))
+
+	m.SetSortMode(SortDocument)
+	assert.Equal(t, []string{"b", "a", "c"}, sortedValues(m.nodes))
+	assert.Equal(t, []string{"z", "y"}, sortedValues(m.nodes[2].Children))
+}
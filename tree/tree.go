@@ -3,37 +3,90 @@ package tree
 // copied from https://github.com/savannahostrowski/tree-bubble/blob/main/tree.go
 
 import (
-	"fmt"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/sirupsen/logrus"
+	"github.com/charmbracelet/x/ansi"
 )
 
 const (
-	bottomLeft string = " └──"
-
 	white  = lipgloss.Color("#ffffff")
 	black  = lipgloss.Color("#000000")
 	purple = lipgloss.Color("#bd93f9")
+	yellow = lipgloss.Color("#f1fa8c")
+	green  = lipgloss.Color("#50fa7b")
+	orange = lipgloss.Color("#ffb86c")
+	pink   = lipgloss.Color("#ff79c6")
+	cyan   = lipgloss.Color("#8be9fd")
+	grey   = lipgloss.Color("#6272a4")
+	red    = lipgloss.Color("#ff5555")
 )
 
 type Styles struct {
+	// Guides are the glyphs connecting nodes to their parents, drawn in the Shapes style
+	Guides     Guides
 	Shapes     lipgloss.Style
 	Selected   lipgloss.Style
 	Unselected lipgloss.Style
+	Match      lipgloss.Style
 	Help       lipgloss.Style
+
+	// String, Number, Boolean and Null style the values of nodes with those types
+	String  lipgloss.Style
+	Number  lipgloss.Style
+	Boolean lipgloss.Style
+	Null    lipgloss.Style
+	// Container styles the summary of the values in an array or object
+	Container lipgloss.Style
+	// Badge styles the size shown before arrays and objects
+	Badge lipgloss.Style
+	// Detail styles the border of the detail pane
+	Detail lipgloss.Style
+	// Breadcrumb styles the ancestors of the selected node in the header
+	Breadcrumb lipgloss.Style
+
+	// Added, Removed, Changed and Moved style the nodes of a diff with those changes
+	Added   lipgloss.Style
+	Removed lipgloss.Style
+	Changed lipgloss.Style
+	Moved   lipgloss.Style
+	// Flash highlights the nodes changed by a reload
+	Flash lipgloss.Style
+	// Picked styles the marker before picked nodes
+	Picked lipgloss.Style
 }
 
 func defaultStyles() Styles {
 	return Styles{
+		Guides:     UnicodeGuides,
 		Shapes:     lipgloss.NewStyle().Margin(0, 0, 0, 0).Foreground(purple),
 		Selected:   lipgloss.NewStyle().Margin(0, 0, 0, 0).Background(purple),
 		Unselected: lipgloss.NewStyle().Margin(0, 0, 0, 0).Foreground(lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"}),
+		Match:      lipgloss.NewStyle().Margin(0, 0, 0, 0).Foreground(black).Background(yellow),
 		Help:       lipgloss.NewStyle().Margin(0, 0, 0, 0).Foreground(lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"}),
+
+		String:    lipgloss.NewStyle().Foreground(green),
+		Number:    lipgloss.NewStyle().Foreground(orange),
+		Boolean:   lipgloss.NewStyle().Foreground(pink),
+		Null:      lipgloss.NewStyle().Foreground(grey).Italic(true),
+		Container: lipgloss.NewStyle().Foreground(grey),
+		Badge:     lipgloss.NewStyle().Foreground(cyan),
+		Detail:    lipgloss.NewStyle().BorderForeground(purple),
+
+		Breadcrumb: lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"}),
+
+		Added:   lipgloss.NewStyle().Foreground(green),
+		Removed: lipgloss.NewStyle().Foreground(red).Strikethrough(true),
+		Changed: lipgloss.NewStyle().Foreground(yellow),
+		Moved:   lipgloss.NewStyle().Foreground(cyan),
+		Flash:   lipgloss.NewStyle().Foreground(black).Background(orange),
+		Picked:  lipgloss.NewStyle().Foreground(pink),
 	}
 }
 
@@ -43,6 +96,19 @@ type Node struct {
 	Desc     string
 	Children []*Node
 	Expand   bool
+	// Parent is set when the node is given to a model, and is nil for top level nodes
+	Parent *Node
+	// InArray is set when Value is the index of the node within an array rather than a key
+	InArray bool
+	// Data is the value the node was created from
+	Data any
+	// Type is the kind of value in Data, which chooses the style it is drawn in
+	Type ValueType
+	// Change is how the node differs from another document, for trees built from a diff
+	Change Change
+	// Synthetic is set on top level nodes which are not part of the document,
+	// such as the lines of an NDJSON stream, and are left out of paths
+	Synthetic bool
 }
 
 type Model struct {
@@ -52,18 +118,54 @@ type Model struct {
 	width  int
 	height int
 	nodes  []*Node
-	cursor int
-
+	// cursor is the row of currentNode among the visible nodes. The selected
+	// node is the source of truth, and cursor is recomputed from it whenever
+	// the visible rows change.
+	cursor      int
 	currentNode *Node
+	rows        rowCache
+	scroll      scroll
+
+	search      search
+	filter      filter
+	queryPrompt queryPrompt
+	sort        sorter
+	detail      detail
+	breadcrumb  breadcrumb
+	zoom        zoom
+	flash       flash
+	pick        pick
+
+	// PathFormat is the syntax used when copying the path of a node
+	PathFormat PathFormat
+	// ShowBadges draws the size of arrays and objects before their values, and quotes strings
+	ShowBadges bool
+	// ShowBreadcrumb draws the path to the selected node above the tree
+	ShowBreadcrumb bool
+	// ShowChanges draws a marker before each node for how it changed, and a
+	// summary of the changes, for trees built from a diff. Set it with SetShowChanges.
+	ShowChanges bool
+	changes     changes
+	// MultiPick allows several nodes to be picked, marking each picked node.
+	// Set it with SetMultiPick.
+	MultiPick bool
+	// status is a transient message shown above the help
+	status string
 
 	Help     help.Model
 	showHelp bool
 
 	AdditionalShortHelpKeys func() []key.Binding
+	// Query produces the nodes to display for a query entered at the query prompt
+	Query func(query string) ([]*Node, error)
+	// Output is the terminal the program renders to, which copies to the
+	// clipboard are written to. It is stdout if nil.
+	Output io.Writer
 }
 
 func New(nodes []*Node, width int, height int) *Model {
-	return &Model{
+	linkParents(nodes, nil)
+	m := &Model{
 		KeyMap: DefaultKeyMap(),
 		Styles: defaultStyles(),
 
@@ -71,21 +173,82 @@ func New(nodes []*Node, width int, height int) *Model {
 		height: height,
 		nodes:  nodes,
 
+		search:      newSearch(),
+		filter:      newFilter(),
+		queryPrompt: newQueryPrompt(),
+		detail:      newDetail(),
+
+		ShowBadges:     true,
+		ShowBreadcrumb: true,
+
 		showHelp: true,
 		Help:     help.New(),
 	}
+	m.currentNode = m.nodeAt(0)
+	return m
 }
 
 // KeyMap holds the key bindings for the table.
 type KeyMap struct {
-	Bottom      key.Binding
-	Top         key.Binding
-	SectionDown key.Binding
-	SectionUp   key.Binding
-	Down        key.Binding
-	Up          key.Binding
-	Quit        key.Binding
-	Collapse    key.Binding
+	Bottom       key.Binding
+	Top          key.Binding
+	PageDown     key.Binding
+	PageUp       key.Binding
+	HalfPageDown key.Binding
+	HalfPageUp   key.Binding
+	SectionDown  key.Binding
+	SectionUp    key.Binding
+	Parent       key.Binding
+	Down         key.Binding
+	Up           key.Binding
+	Quit         key.Binding
+	Collapse     key.Binding
+
+	ExpandAll       key.Binding
+	CollapseAll     key.Binding
+	ExpandSubtree   key.Binding
+	CollapseSubtree key.Binding
+	ExpandDepth     key.Binding
+
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
+	AcceptSearch key.Binding
+	CancelSearch key.Binding
+
+	Filter           key.Binding
+	AcceptFilter     key.Binding
+	CancelFilter     key.Binding
+	ToggleRegex      key.Binding
+	ToggleIgnoreCase key.Binding
+
+	Query       key.Binding
+	AcceptQuery key.Binding
+	CancelQuery key.Binding
+
+	CopyPath        key.Binding
+	CyclePathFormat key.Binding
+	CopyValue       key.Binding
+	CopyJSON        key.Binding
+
+	Sort key.Binding
+
+	ToggleDetail key.Binding
+	DetailDown   key.Binding
+	DetailUp     key.Binding
+	ToggleWrap   key.Binding
+
+	Jump       key.Binding
+	CancelJump key.Binding
+
+	ZoomIn  key.Binding
+	ZoomOut key.Binding
+
+	NextChange        key.Binding
+	PrevChange        key.Binding
+	ToggleChangesOnly key.Binding
+
+	Pick key.Binding
 
 	ShowFullHelp  key.Binding
 	CloseFullHelp key.Binding
@@ -95,20 +258,40 @@ type KeyMap struct {
 func DefaultKeyMap() KeyMap {
 	return KeyMap{
 		Bottom: key.NewBinding(
-			key.WithKeys("bottom"),
-			key.WithHelp("end", "bottom"),
+			key.WithKeys("end", "G"),
+			key.WithHelp("G/end", "bottom"),
 		),
 		Top: key.NewBinding(
-			key.WithKeys("top"),
-			key.WithHelp("home", "top"),
+			key.WithKeys("home", "g"),
+			key.WithHelp("g/home", "top"),
+		),
+		PageDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdn", "page down"),
+		),
+		PageUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "page up"),
+		),
+		HalfPageDown: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "half page down"),
+		),
+		HalfPageUp: key.NewBinding(
+			key.WithKeys("ctrl+u"),
+			key.WithHelp("ctrl+u", "half page up"),
 		),
 		SectionDown: key.NewBinding(
-			key.WithKeys("secdown"),
-			key.WithHelp("secdown", "section down"),
+			key.WithKeys("]"),
+			key.WithHelp("]", "next sibling"),
 		),
 		SectionUp: key.NewBinding(
-			key.WithKeys("secup"),
-			key.WithHelp("secup", "section up"),
+			key.WithKeys("["),
+			key.WithHelp("[", "previous sibling"),
+		),
+		Parent: key.NewBinding(
+			key.WithKeys("u", "backspace"),
+			key.WithHelp("u", "parent"),
 		),
 		Down: key.NewBinding(
 			key.WithKeys("down", "j"),
@@ -119,10 +302,161 @@ func DefaultKeyMap() KeyMap {
 			key.WithHelp("↑", "up"),
 		),
 		Collapse: key.NewBinding(
-			key.WithKeys("n"),
+			key.WithKeys("tab"),
 			key.WithHelp("tab", "collapse"),
 		),
 
+		ExpandAll: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "expand all"),
+		),
+		CollapseAll: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "collapse all"),
+		),
+		ExpandSubtree: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "expand subtree"),
+		),
+		CollapseSubtree: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "collapse subtree"),
+		),
+		ExpandDepth: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "expand to depth"),
+		),
+
+		Search: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "search"),
+		),
+		NextMatch: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next match"),
+		),
+		PrevMatch: key.NewBinding(
+			key.WithKeys("N"),
+			key.WithHelp("N", "previous match"),
+		),
+		AcceptSearch: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "accept search"),
+		),
+		CancelSearch: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear search"),
+		),
+
+		Filter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "filter"),
+		),
+		AcceptFilter: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "accept filter"),
+		),
+		CancelFilter: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear filter"),
+		),
+		ToggleRegex: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "toggle regex"),
+		),
+		ToggleIgnoreCase: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "toggle ignore case"),
+		),
+
+		Query: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "query"),
+		),
+		AcceptQuery: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "run query"),
+		),
+		CancelQuery: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel query"),
+		),
+
+		CopyPath: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy path"),
+		),
+		CyclePathFormat: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "path format"),
+		),
+		CopyValue: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "copy value"),
+		),
+		CopyJSON: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "copy as JSON"),
+		),
+
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+
+		ToggleDetail: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "detail"),
+		),
+		DetailDown: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "scroll detail down"),
+		),
+		DetailUp: key.NewBinding(
+			key.WithKeys("K"),
+			key.WithHelp("K", "scroll detail up"),
+		),
+		ToggleWrap: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "wrap detail"),
+		),
+
+		Jump: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "jump to ancestor"),
+		),
+		CancelJump: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel jump"),
+		),
+
+		ZoomIn: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "zoom in"),
+		),
+		ZoomOut: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "zoom out"),
+		),
+
+		NextChange: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "next change"),
+		),
+		PrevChange: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "previous change"),
+		),
+		ToggleChangesOnly: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "only changes"),
+		),
+
+		Pick: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "pick"),
+		),
+
 		ShowFullHelp: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "more"),
@@ -144,27 +478,39 @@ func (m Model) Nodes() []*Node {
 }
 
 func (m *Model) SetNodes(nodes []*Node) {
+	m.restoreOrder()
+	linkParents(nodes, nil)
+	// keep the selection on the node at the same path in the new nodes, if there is one
+	var selected *Node
+	if m.currentNode != nil {
+		selected = findPath(nodes, m.currentNode.Path())
+	}
 	m.nodes = nodes
-}
-
-func (m *Model) NumberOfNodes() int {
-	count := 0
-
-	var countNodes func([]*Node)
-	countNodes = func(nodes []*Node) {
-		for _, node := range nodes {
-			count++
-			if node.Children != nil && node.Expand {
-				// Recursively count the children, if expanded
-				countNodes(node.Children)
-			}
-		}
+	m.cursor = 0
+	m.currentNode = nil
+	m.remapZoom(nodes)
+	if m.zoom.root != nil {
+		m.zoom.root.Expand = true
 	}
+	m.invalidateRows()
+	// the sort, filter and search refer to the previous nodes, so reapply them to the new ones
+	if m.sort.mode != SortDocument {
+		m.SetSortMode(m.sort.mode)
+	}
+	m.filter.visible, m.filter.expanded, m.filter.selected = nil, nil, nil
+	m.SetFilter(m.filter.query)
+	m.selectNode(selected)
+	m.findMatches()
+	m.countChanges()
+}
 
-	countNodes(m.nodes)
-
-	return count
+// SetStatus shows a transient message above the help, until the next key press.
+func (m *Model) SetStatus(status string) {
+	m.status = status
+}
 
+func (m *Model) NumberOfNodes() int {
+	return len(m.visibleRows())
 }
 
 func (m Model) Width() int {
@@ -178,6 +524,7 @@ func (m Model) Height() int {
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
+	m.Help.Width = width
 }
 
 func (m *Model) SetWidth(newWidth int) {
@@ -192,49 +539,208 @@ func (m Model) Cursor() int {
 	return m.cursor
 }
 
+// SetCursor selects the node at the given row.
 func (m *Model) SetCursor(cursor int) {
-	m.cursor = cursor
+	m.MoveCursor(cursor - m.cursor)
 }
 
-func (m *Model) SetShowHelp() bool {
-	return m.showHelp
+// SelectedNode returns the node under the cursor, or nil if the tree is empty.
+func (m *Model) SelectedNode() *Node {
+	m.syncSelection()
+	return m.currentNode
 }
 
-func (m *Model) NavUp() {
-	m.cursor--
+// SelectNode moves the cursor to node, expanding its ancestors so it is visible.
+// It returns false if node is not in the tree or is hidden by the filter.
+func (m *Model) SelectNode(node *Node) bool {
+	if node == nil || !m.contains(node) || !m.shown(node) {
+		return false
+	}
+	m.expandAncestors(node)
+	m.selectNode(node)
+	return true
+}
 
-	if m.cursor < 0 {
-		m.cursor = 0
-		return
+// contains returns true if node is in the tree, and within the zoomed subtree if there is one.
+func (m *Model) contains(node *Node) bool {
+	path := node.Path()
+	if m.zoom.root != nil {
+		return slices.Contains(path, m.zoom.root)
 	}
+	return slices.Contains(m.nodes, path[0])
+}
 
+// syncSelection recomputes the cursor from the selected node. If the node has
+// been hidden, for example by collapsing its parent, its closest visible
+// ancestor is selected instead.
+func (m *Model) syncSelection() {
+	m.selectNode(m.currentNode)
 }
 
-func (m *Model) NavDown() {
-	m.cursor++
+// nodeAt returns the visible node at row idx, or nil if there is none.
+func (m *Model) nodeAt(idx int) *Node {
+	rows := m.visibleRows()
+	if idx < 0 || idx >= len(rows) {
+		return nil
+	}
+	return rows[idx].node
+}
 
-	if m.cursor >= m.NumberOfNodes() {
-		m.cursor = m.NumberOfNodes() - 1
-		return
+// selectNode moves the cursor to node, or the closest valid row if it is not visible.
+func (m *Model) selectNode(node *Node) {
+	for ; node != nil; node = node.Parent {
+		if idx := m.indexOf(node); idx >= 0 {
+			m.cursor = idx
+			m.currentNode = node
+			return
+		}
 	}
+	// nothing on the path to node is visible, so stay on the same row
+	m.MoveCursor(0)
+}
+
+// Prompting returns true while a search, filter or query prompt, or the
+// ancestor jump, is capturing key presses.
+func (m *Model) Prompting() bool {
+	return m.Searching() || m.EditingFilter() || m.Querying() || m.Jumping()
+}
+
+func (m *Model) SetShowHelp() bool {
+	return m.showHelp
+}
+
+func (m *Model) NavUp() {
+	m.MoveCursor(-1)
+}
+
+func (m *Model) NavDown() {
+	m.MoveCursor(1)
 }
 
 func (m *Model) InvertCollaped() {
-	if m.currentNode.Children != nil {
-		m.currentNode.Expand = !m.currentNode.Expand
+	node := m.SelectedNode()
+	if node != nil && node.Children != nil {
+		m.SetExpand(node, !node.Expand)
 	}
 }
 
 func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.SetSize(msg.Width, msg.Height)
+	case CopiedMsg:
+		if msg.Err != nil {
+			m.status = "copy failed: " + msg.Err.Error()
+		} else {
+			m.status = msg.Description
+		}
+	case tea.MouseMsg:
+		return m, m.updateMouse(msg)
+	case flashDoneMsg:
+		if msg.generation == m.flash.generation {
+			m.flash.nodes, m.flash.inside = nil, nil
+		}
 	case tea.KeyMsg:
+		m.status = ""
+		// the view follows the cursor again once a key is pressed
+		m.scroll.active = false
+		// node expand states may have been changed since the last update
+		m.syncSelection()
+		if m.Searching() {
+			return m, m.updateSearch(msg)
+		}
+		if m.EditingFilter() {
+			return m, m.updateFilter(msg)
+		}
+		if m.Querying() {
+			return m, m.updateQuery(msg)
+		}
+		if m.Jumping() {
+			return m, m.updateJump(msg)
+		}
 		switch {
 		case key.Matches(msg, m.KeyMap.Up):
 			m.NavUp()
 		case key.Matches(msg, m.KeyMap.Down):
 			m.NavDown()
+		case key.Matches(msg, m.KeyMap.Top):
+			m.NavTop()
+		case key.Matches(msg, m.KeyMap.Bottom):
+			m.NavBottom()
+		case key.Matches(msg, m.KeyMap.PageUp):
+			m.PageUp()
+		case key.Matches(msg, m.KeyMap.PageDown):
+			m.PageDown()
+		case key.Matches(msg, m.KeyMap.HalfPageUp):
+			m.HalfPageUp()
+		case key.Matches(msg, m.KeyMap.HalfPageDown):
+			m.HalfPageDown()
+		case key.Matches(msg, m.KeyMap.SectionUp):
+			m.PrevSibling()
+		case key.Matches(msg, m.KeyMap.SectionDown):
+			m.NextSibling()
+		case key.Matches(msg, m.KeyMap.Jump):
+			m.StartJump()
+		case key.Matches(msg, m.KeyMap.ZoomIn):
+			m.ZoomIn()
+		case key.Matches(msg, m.KeyMap.ZoomOut):
+			m.ZoomOut()
+		case key.Matches(msg, m.KeyMap.NextChange) && m.ShowChanges:
+			m.NextChange()
+		case key.Matches(msg, m.KeyMap.PrevChange) && m.ShowChanges:
+			m.PrevChange()
+		case key.Matches(msg, m.KeyMap.ToggleChangesOnly) && m.ShowChanges:
+			m.SetChangesOnly(!m.filter.changesOnly)
+		case key.Matches(msg, m.KeyMap.Pick) && m.MultiPick:
+			m.TogglePick()
+		case key.Matches(msg, m.KeyMap.Parent):
+			m.NavParent()
 		case key.Matches(msg, m.KeyMap.Collapse):
 			m.InvertCollaped()
+		case key.Matches(msg, m.KeyMap.ExpandAll):
+			m.ExpandAll()
+		case key.Matches(msg, m.KeyMap.CollapseAll):
+			m.CollapseAll()
+		case key.Matches(msg, m.KeyMap.ExpandSubtree):
+			m.ExpandSubtree()
+		case key.Matches(msg, m.KeyMap.CollapseSubtree):
+			m.CollapseSubtree()
+		case key.Matches(msg, m.KeyMap.ExpandDepth):
+			if depth, err := strconv.Atoi(msg.String()); err == nil {
+				m.ExpandToDepth(depth)
+			}
+		case key.Matches(msg, m.KeyMap.Search):
+			return m, m.StartSearch()
+		case key.Matches(msg, m.KeyMap.NextMatch):
+			m.NextMatch()
+		case key.Matches(msg, m.KeyMap.PrevMatch):
+			m.PrevMatch()
+		case key.Matches(msg, m.KeyMap.Filter):
+			return m, m.StartFilter()
+		case key.Matches(msg, m.KeyMap.Query):
+			return m, m.StartQuery()
+		case key.Matches(msg, m.KeyMap.CopyPath):
+			return m, m.CopyPath()
+		case key.Matches(msg, m.KeyMap.CyclePathFormat):
+			m.CyclePathFormat()
+		case key.Matches(msg, m.KeyMap.CopyValue):
+			return m, m.CopyValue()
+		case key.Matches(msg, m.KeyMap.CopyJSON):
+			return m, m.CopyJSON()
+		case key.Matches(msg, m.KeyMap.Sort):
+			m.CycleSortMode()
+		case key.Matches(msg, m.KeyMap.ToggleDetail):
+			m.ToggleDetail()
+		case key.Matches(msg, m.KeyMap.DetailDown) && m.detail.open:
+			m.ScrollDetail(1)
+		case key.Matches(msg, m.KeyMap.DetailUp) && m.detail.open:
+			m.ScrollDetail(-1)
+		case key.Matches(msg, m.KeyMap.ToggleWrap) && m.detail.open:
+			m.ToggleDetailWrap()
+		case key.Matches(msg, m.KeyMap.CancelSearch) && m.search.query != "":
+			m.ClearSearch()
+		case key.Matches(msg, m.KeyMap.CancelFilter) && m.filter.query != "":
+			m.ClearFilter()
 		case key.Matches(msg, m.KeyMap.ShowFullHelp):
 			fallthrough
 		case key.Matches(msg, m.KeyMap.CloseFullHelp):
@@ -250,6 +756,7 @@ func (m *Model) View() string {
 	var sections []string
 
 	nodes := m.Nodes()
+	m.syncSelection()
 
 	var help string
 	if m.showHelp {
@@ -257,8 +764,15 @@ func (m *Model) View() string {
 		availableHeight -= lipgloss.Height(help)
 	}
 
-	count := 0 // This is used to keep track of the index of the node we are on (important because we are using a recursive function)
-	sections = append(sections, lipgloss.NewStyle().Height(availableHeight).Render(m.renderTree(m.nodes, 0, &count)), help)
+	// the tree starts below the header, which mouse positions are offset by
+	m.scroll.top = 0
+	if m.ShowBreadcrumb {
+		header := m.breadcrumbView()
+		m.scroll.top = lipgloss.Height(header)
+		availableHeight -= m.scroll.top
+		sections = append(sections, header)
+	}
+	sections = append(sections, m.mainView(availableHeight), help)
 
 	if len(nodes) == 0 {
 		return "No data"
@@ -266,60 +780,83 @@ func (m *Model) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
-func (m *Model) getDisplayRange(maxRows int) (int, int) {
-	rowsAbove := m.height / 2
-	rowsBelow := m.height / 2
-	if m.cursor < rowsAbove {
-		rowsAbove = m.cursor
-		rowsBelow = m.height - m.cursor
+// mainView renders the tree, and the detail pane if it is open, height lines tall.
+func (m *Model) mainView(height int) string {
+	if !m.detail.open {
+		return lipgloss.NewStyle().Height(height).Render(m.renderTree(height))
 	}
-	if m.cursor+rowsBelow > maxRows {
-		rowsBelow = maxRows - m.cursor
-		rowsAbove = m.height - rowsBelow
+	if m.detailSide() {
+		width := m.treeWidth()
+		tree := lipgloss.NewStyle().Width(width).Height(height).Render(m.renderTree(height))
+		return lipgloss.JoinHorizontal(lipgloss.Top, tree, m.detailView(m.width-width, height))
 	}
-	return m.cursor - rowsAbove, m.cursor + rowsBelow
+	treeHeight := height - height/2
+	tree := lipgloss.NewStyle().Height(treeHeight).Render(m.renderTree(treeHeight))
+	return lipgloss.JoinVertical(lipgloss.Left, tree, m.detailView(m.width, height-treeHeight))
 }
 
-func (m *Model) renderTree(remainingNodes []*Node, indent int, count *int) string {
-	var b strings.Builder
-
-	minRow, maxRow := m.getDisplayRange(m.NumberOfNodes())
-
-	for _, node := range remainingNodes {
+// displayRange returns the first row to draw and the row after the last, for
+// a view height rows tall. The view is centred on the cursor unless it has been
+// scrolled with the mouse.
+func (m *Model) displayRange(maxRows int, height int) (int, int) {
+	first := m.cursor - height/2
+	if m.scroll.active {
+		first = m.scroll.offset
+	}
+	first = max(min(first, maxRows-height), 0)
+	m.scroll.offset = first
+	return first, min(first+height, maxRows)
+}
 
-		var str string
+func (m *Model) renderTree(height int) string {
+	var b strings.Builder
 
-		// If we aren't at the root, we add the arrow shape to the string
-		if indent > 0 {
-			shape := strings.Repeat(" ", (indent-1)*2) + m.Styles.Shapes.Render(bottomLeft) + " "
-			str += shape
-		}
+	rows := m.visibleRows()
+	minRow, maxRow := m.displayRange(len(rows), height)
+	m.scroll.first, m.scroll.height = minRow, height
+	if minRow >= maxRow {
+		return ""
+	}
+	keyWidth, descWidth := m.columnWidths(rows[minRow:maxRow])
 
-		// Generate the correct index for the node
-		idx := *count
-		*count++
+	for idx := minRow; idx < maxRow; idx++ {
+		node, depth := rows[idx].node, rows[idx].depth
 
-		// Format the string with fixed width for the value and description fields
-		valueWidth := 10
-		descWidth := 20
-		valueStr := strings.ReplaceAll(fmt.Sprintf("%-*s", valueWidth, node.Value), "\n", " ")
-		descStr := strings.ReplaceAll(fmt.Sprintf("%-*s", descWidth, node.Desc), "\n", " ")
+		// If we aren't at the root, we add the lines connecting the node to its parent
+		shape := m.guide(node, depth)
+		str := m.gutter(node) + m.Styles.Shapes.Render(shape)
 
 		// If we are at the cursor, we add the selected style to the string
+		keyStyle, valueStyle, badgeStyle := m.Styles.Unselected, m.valueStyle(node), m.Styles.Badge
+		if node.Change != ChangeNone {
+			keyStyle = m.changeStyle(node.Change)
+		}
+		if m.flashed(node) {
+			keyStyle, valueStyle, badgeStyle = m.Styles.Flash, m.Styles.Flash, m.Styles.Flash
+		}
 		if m.cursor == idx {
-			m.currentNode = node
-			str += fmt.Sprintf("%s\t\t%s\n", m.Styles.Selected.Render(valueStr), m.Styles.Selected.Render(descStr))
-		} else if idx >= minRow && idx <= maxRow {
-			str += fmt.Sprintf("%s\t\t%s\n", m.Styles.Unselected.Render(valueStr), m.Styles.Unselected.Render(descStr))
-		} else {
-			logrus.Debugf("Skipping node %d: %s", idx, node.Value)
+			keyStyle, valueStyle, badgeStyle = m.Styles.Selected, m.Styles.Selected, m.Styles.Selected
 		}
 
-		b.WriteString(str)
+		// The key is padded to line up the values, each cut to fit the width with an ellipsis
+		str += m.highlight(pad(cellText(node.Value), max(keyWidth-m.gutterWidth()-ansi.StringWidth(shape), 1)), keyStyle)
+		if descWidth > 0 {
+			str += strings.Repeat(" ", columnGap)
+			remaining := descWidth
+			if badge := m.badge(node); badge != "" {
+				badge = truncate(badge, remaining)
+				str += badgeStyle.Render(badge)
+				remaining -= ansi.StringWidth(badge) + 1
+				if remaining > 0 {
+					str += valueStyle.Render(" ")
+				}
+			}
+			str += m.highlight(truncate(m.displayDesc(node), remaining), valueStyle)
+		}
 
-		if node.Children != nil && node.Expand {
-			childStr := m.renderTree(node.Children, indent+1, count)
-			b.WriteString(childStr)
+		b.WriteString(ansi.Truncate(str, m.treeWidth(), ""))
+		if idx < maxRow-1 {
+			b.WriteString("\n")
 		}
 	}
 
@@ -327,14 +864,42 @@ func (m *Model) renderTree(remainingNodes []*Node, indent int, count *int) strin
 }
 
 func (m *Model) helpView() string {
-	return m.Styles.Help.Render(m.Help.View(m))
+	sections := []string{}
+	if m.ShowChanges {
+		sections = append(sections, m.changeSummary())
+	}
+	if m.MultiPick {
+		sections = append(sections, m.pickSummary())
+	}
+	if m.status != "" {
+		sections = append(sections, m.Styles.Help.Render(m.status))
+	}
+	if query := m.queryView(); query != "" {
+		sections = append(sections, query)
+	}
+	if filter := m.filterView(); filter != "" {
+		sections = append(sections, filter)
+	}
+	if search := m.searchView(); search != "" {
+		sections = append(sections, search)
+	}
+	sections = append(sections, m.Styles.Help.Render(m.Help.View(m)))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
 func (m *Model) ShortHelp() []key.Binding {
 	kb := []key.Binding{
 		m.KeyMap.Up,
 		m.KeyMap.Down,
+		m.KeyMap.Top,
+		m.KeyMap.Bottom,
 		m.KeyMap.Collapse,
+		m.KeyMap.Search,
+		m.KeyMap.Filter,
+	}
+
+	if m.MultiPick {
+		kb = append(kb, m.KeyMap.Pick)
 	}
 
 	if m.AdditionalShortHelpKeys != nil {
@@ -350,9 +915,65 @@ func (m *Model) FullHelp() [][]key.Binding {
 	kb := [][]key.Binding{{
 		m.KeyMap.Up,
 		m.KeyMap.Down,
+		m.KeyMap.Top,
+		m.KeyMap.Bottom,
+		m.KeyMap.PageUp,
+		m.KeyMap.PageDown,
+		m.KeyMap.HalfPageUp,
+		m.KeyMap.HalfPageDown,
+	}, {
+		m.KeyMap.SectionUp,
+		m.KeyMap.SectionDown,
+		m.KeyMap.Parent,
+		m.KeyMap.Jump,
+		m.KeyMap.ZoomIn,
+		m.KeyMap.ZoomOut,
+		m.KeyMap.Sort,
+	}, {
 		m.KeyMap.Collapse,
+		m.KeyMap.ExpandSubtree,
+		m.KeyMap.CollapseSubtree,
+		m.KeyMap.ExpandAll,
+		m.KeyMap.CollapseAll,
+		m.KeyMap.ExpandDepth,
+	}, {
+		m.KeyMap.Search,
+		m.KeyMap.NextMatch,
+		m.KeyMap.PrevMatch,
+		m.KeyMap.CancelSearch,
+	}, {
+		m.KeyMap.Filter,
+		m.KeyMap.ToggleRegex,
+		m.KeyMap.ToggleIgnoreCase,
+		m.KeyMap.CancelFilter,
+	}, {
+		m.KeyMap.CopyPath,
+		m.KeyMap.CyclePathFormat,
+		m.KeyMap.CopyValue,
+		m.KeyMap.CopyJSON,
+	}, {
+		m.KeyMap.ToggleDetail,
+		m.KeyMap.DetailDown,
+		m.KeyMap.DetailUp,
+		m.KeyMap.ToggleWrap,
 	}}
 
+	if m.ShowChanges {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.NextChange,
+			m.KeyMap.PrevChange,
+			m.KeyMap.ToggleChangesOnly,
+		})
+	}
+
+	if m.MultiPick {
+		kb = append(kb, []key.Binding{m.KeyMap.Pick})
+	}
+
+	if m.Query != nil {
+		kb = append(kb, []key.Binding{m.KeyMap.Query})
+	}
+
 	return append(kb,
 		[]key.Binding{
 			m.KeyMap.Quit,
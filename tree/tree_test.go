@@ -0,0 +1,58 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvertCollapsedBeforeView(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SetCursor(1)
+	m.InvertCollaped()
+	assert.True(t, m.nodes[1].Expand)
+
+	empty := New(nil, 80, 24)
+	assert.NotPanics(t, empty.InvertCollaped)
+	assert.Nil(t, empty.SelectedNode())
+}
+
+func TestSelectionFollowsNode(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.ExpandAll()
+	m.SetCursor(7)
+	assert.Equal(t, "asn", m.SelectedNode().Value)
+
+	// collapsing a parent above the cursor keeps the same node selected
+	m.SetExpand(m.nodes[1], false)
+	assert.Equal(t, "asn", m.SelectedNode().Value)
+	assert.Equal(t, 2, m.Cursor())
+
+	assert.True(t, m.SelectNode(m.nodes[1].Children[2].Children[1]))
+	assert.Equal(t, "longitude", m.SelectedNode().Value)
+	assert.Equal(t, 6, m.Cursor())
+
+	// hiding the selected node selects its closest visible ancestor
+	m.SetExpand(m.nodes[1].Children[2], false)
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+
+	assert.False(t, m.SelectNode(&Node{Value: "ip"}))
+	m.SetFilter("asn")
+	assert.False(t, m.SelectNode(m.nodes[0]))
+}
+
+func TestSetNodesKeepsSelection(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.ExpandAll()
+	m.SetCursor(3)
+	assert.Equal(t, "country", m.SelectedNode().Value)
+
+	nodes := testNodes()
+	nodes[1].Expand = true
+	m.SetNodes(nodes)
+	assert.Same(t, nodes[1].Children[1], m.SelectedNode())
+	assert.Equal(t, 3, m.Cursor())
+
+	m.SetNodes([]*Node{{Value: "other"}})
+	assert.Equal(t, "other", m.SelectedNode().Value)
+}
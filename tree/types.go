@@ -0,0 +1,75 @@
+package tree
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ValueType is the kind of value a node holds, used to choose its style.
+type ValueType int
+
+const (
+	TypeUnknown ValueType = iota
+	TypeString
+	TypeNumber
+	TypeBoolean
+	TypeNull
+	TypeArray
+	TypeObject
+)
+
+var valueTypeNames = []string{"unknown", "string", "number", "boolean", "null", "array", "object"}
+
+func (t ValueType) String() string {
+	if t >= 0 && int(t) < len(valueTypeNames) {
+		return valueTypeNames[t]
+	}
+	return "ValueType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// valueStyle returns the style for the value of node when it is not selected.
+// Changed nodes are drawn in the style of their change.
+func (m *Model) valueStyle(node *Node) lipgloss.Style {
+	if node.Change != ChangeNone {
+		return m.changeStyle(node.Change)
+	}
+	switch node.Type {
+	case TypeString:
+		return m.Styles.String
+	case TypeNumber:
+		return m.Styles.Number
+	case TypeBoolean:
+		return m.Styles.Boolean
+	case TypeNull:
+		return m.Styles.Null
+	case TypeArray, TypeObject:
+		return m.Styles.Container
+	}
+	return m.Styles.Unselected
+}
+
+// badge returns the marker shown before the value of node when badges are
+// enabled, with the number of keys in an object or items in an array.
+func (m *Model) badge(node *Node) string {
+	if !m.ShowBadges {
+		return ""
+	}
+	switch node.Type {
+	case TypeObject:
+		return "{" + strconv.Itoa(len(node.Children)) + "}"
+	case TypeArray:
+		return "[" + strconv.Itoa(len(node.Children)) + "]"
+	}
+	return ""
+}
+
+// displayDesc returns the description of node as it is displayed, quoting
+// strings when badges are enabled.
+func (m *Model) displayDesc(node *Node) string {
+	// descriptions which add to the string, such as YAML anchors, are left unquoted
+	if s, ok := node.Data.(string); ok && m.ShowBadges && node.Type == TypeString && node.Desc == s {
+		return cellText(strconv.Quote(s))
+	}
+	return cellText(node.Desc)
+}
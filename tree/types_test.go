@@ -0,0 +1,57 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func typedNodes() []*Node {
+	return []*Node{
+		{Value: "name", Desc: "Ada", Data: "Ada", Type: TypeString},
+		{Value: "age", Desc: "36", Data: 36, Type: TypeNumber},
+		{Value: "admin", Desc: "true", Data: true, Type: TypeBoolean},
+		{Value: "manager", Desc: "null", Type: TypeNull},
+		{Value: "tags", Desc: "a b", Type: TypeArray, Children: []*Node{
+			{Value: "0", Desc: "a", Data: "a", Type: TypeString, InArray: true},
+			{Value: "1", Desc: "b", Data: "b", Type: TypeString, InArray: true},
+		}},
+		{Value: "address", Desc: "London", Type: TypeObject, Children: []*Node{
+			{Value: "city", Desc: "London", Data: "London", Type: TypeString},
+		}},
+		{Value: "anchor", Desc: "&ref London", Data: "London", Type: TypeString},
+	}
+}
+
+func TestBadges(t *testing.T) {
+	m := New(typedNodes(), 80, 24)
+	lines := strings.Split(ansi.Strip(m.renderTree(m.Height())), "\n")
+	assert.Equal(t, `name     "Ada"`, lines[0])
+	assert.Equal(t, "age      36", lines[1])
+	assert.Equal(t, "manager  null", lines[3])
+	assert.Equal(t, "tags     [2] a b", lines[4])
+	assert.Equal(t, "address  {1} London", lines[5])
+	assert.Equal(t, "anchor   &ref London", lines[6])
+
+	m.ShowBadges = false
+	lines = strings.Split(ansi.Strip(m.renderTree(m.Height())), "\n")
+	assert.Equal(t, "name     Ada", lines[0])
+	assert.Equal(t, "tags     a b", lines[4])
+}
+
+func TestValueStyle(t *testing.T) {
+	m := New(typedNodes(), 80, 24)
+	nodes := m.Nodes()
+	assert.Equal(t, m.Styles.String, m.valueStyle(nodes[0]))
+	assert.Equal(t, m.Styles.Number, m.valueStyle(nodes[1]))
+	assert.Equal(t, m.Styles.Boolean, m.valueStyle(nodes[2]))
+	assert.Equal(t, m.Styles.Null, m.valueStyle(nodes[3]))
+	assert.Equal(t, m.Styles.Container, m.valueStyle(nodes[4]))
+	assert.Equal(t, m.Styles.Unselected, m.valueStyle(&Node{}))
+	assert.Equal(t, "object", TypeObject.String())
+
+	m.SetSortMode(SortType)
+	assert.Equal(t, []string{"manager", "admin", "age", "name", "anchor", "tags", "address"}, sortedValues(m.nodes))
+}
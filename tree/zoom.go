@@ -0,0 +1,96 @@
+package tree
+
+import "slices"
+
+// zoomLevel is a view of the tree to return to when zooming out.
+type zoomLevel struct {
+	// root is the node shown as the top of the tree, or nil for the whole tree
+	root *Node
+	// selected is the node that was selected before zooming in
+	selected *Node
+}
+
+// zoom tracks the subtree shown in place of the whole tree.
+type zoom struct {
+	root  *Node
+	stack []zoomLevel
+}
+
+// ZoomRoot returns the node shown as the top of the tree, or nil if the whole
+// tree is shown.
+func (m *Model) ZoomRoot() *Node {
+	return m.zoom.root
+}
+
+// ZoomIn shows only the selected node and its descendants, as if it were the
+// whole tree, until ZoomOut is called. It returns false if the node has no
+// children to zoom into.
+func (m *Model) ZoomIn() bool {
+	node := m.currentNode
+	if node == nil || len(node.Children) == 0 || node == m.zoom.root {
+		return false
+	}
+	m.zoom.stack = append(m.zoom.stack, zoomLevel{root: m.zoom.root, selected: node})
+	m.setZoomRoot(node)
+	node.Expand = true
+	m.selectNode(node)
+	return true
+}
+
+// ZoomOut returns to the view before the last ZoomIn, selecting the node that
+// was zoomed into. It returns false if the tree is not zoomed.
+func (m *Model) ZoomOut() bool {
+	if len(m.zoom.stack) == 0 {
+		return false
+	}
+	level := m.zoom.stack[len(m.zoom.stack)-1]
+	m.zoom.stack = m.zoom.stack[:len(m.zoom.stack)-1]
+	m.setZoomRoot(level.root)
+	m.expandAncestors(level.selected)
+	m.selectNode(level.selected)
+	return true
+}
+
+// setZoomRoot shows root as the top of the tree, resetting the view to the start.
+func (m *Model) setZoomRoot(root *Node) {
+	m.zoom.root = root
+	m.scroll.active, m.scroll.offset = false, 0
+	m.invalidateRows()
+	m.findMatches()
+}
+
+// roots returns the top level nodes of the view, which is the zoomed node if
+// there is one.
+func (m *Model) roots() []*Node {
+	if m.zoom.root != nil {
+		return []*Node{m.zoom.root}
+	}
+	return m.nodes
+}
+
+// viewPath returns the path to node from the top of the view.
+func (m *Model) viewPath(node *Node) []*Node {
+	path := node.Path()
+	if i := slices.Index(path, m.zoom.root); i >= 0 {
+		return path[i:]
+	}
+	return path
+}
+
+// remapZoom moves the zoom stack onto the nodes at the same paths in nodes.
+// Levels zoomed into nodes that no longer exist are dropped.
+func (m *Model) remapZoom(nodes []*Node) {
+	var root *Node
+	stack := m.zoom.stack[:0]
+	for _, level := range m.zoom.stack {
+		// each level zooms into the node selected in the one before
+		selected := findPath(nodes, level.selected.Path())
+		if selected == nil {
+			break
+		}
+		stack = append(stack, zoomLevel{root: root, selected: selected})
+		root = selected
+	}
+	m.zoom.stack = stack
+	m.zoom.root = root
+}
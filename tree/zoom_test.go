@@ -0,0 +1,81 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoom(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	assert.False(t, m.ZoomIn())
+	assert.False(t, m.ZoomOut())
+
+	m.NavDown()
+	assert.True(t, m.ZoomIn())
+	assert.Equal(t, m.nodes[1], m.ZoomRoot())
+	assert.Equal(t, []string{"0:location", "1:continent", "1:country", "1:coordinates"}, rowValues(m))
+	assert.Equal(t, "⤢ location", ansi.Strip(m.breadcrumbView()))
+	lines := strings.Split(ansi.Strip(m.renderTree(m.Height())), "\n")
+	assert.Equal(t, "location", strings.Fields(lines[0])[0])
+	assert.True(t, strings.HasPrefix(lines[3], "└── coordinates"))
+
+	// navigation stays inside the zoomed subtree
+	m.NavParent()
+	m.NextSibling()
+	assert.Equal(t, "location", m.SelectedNode().Value)
+	m.NavBottom()
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	assert.Equal(t, "⤢ location › coordinates", ansi.Strip(m.breadcrumbView()))
+	m.ExpandAll()
+	m.NavBottom()
+	assert.Equal(t, "⤢ location › coordinates › longitude", ansi.Strip(m.breadcrumbView()))
+	assert.Equal(t, []string{"0:coordinates", "1:latitude", "1:longitude"}, rowValues(m))
+	assert.False(t, m.SelectNode(m.nodes[0]))
+	m.SetSearch("co")
+	assert.Len(t, m.search.matches, 1)
+
+	// the jump labels start at the zoomed node
+	m.NavBottom()
+	m.StartJump()
+	assert.Equal(t, "⤢ location › 1 coordinates › longitude", ansi.Strip(m.breadcrumbView()))
+	m.JumpToAncestor(0)
+	m.breadcrumb.jumping = false
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+
+	// zooming out selects the node that was zoomed into
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+	assert.Equal(t, 6, m.NumberOfNodes())
+	assert.True(t, m.ZoomOut())
+	assert.Nil(t, m.ZoomRoot())
+	assert.Equal(t, "location", m.SelectedNode().Value)
+	assert.Equal(t, 1, m.Cursor())
+	assert.Len(t, m.search.matches, 3)
+	assert.Equal(t, "location", ansi.Strip(m.breadcrumbView()))
+}
+
+func TestZoomSetNodes(t *testing.T) {
+	m := New(testNodes(), 80, 24)
+	m.SelectNode(m.nodes[1].Children[2])
+	m.ZoomIn()
+	m.NavDown()
+
+	// the zoom follows the nodes at the same path in the new tree
+	m.SetNodes(testNodes())
+	assert.Equal(t, "coordinates", m.ZoomRoot().Value)
+	assert.Equal(t, "latitude", m.SelectedNode().Value)
+	assert.True(t, m.ZoomOut())
+	assert.Equal(t, "coordinates", m.SelectedNode().Value)
+	assert.Same(t, m.nodes[1].Children[2], m.SelectedNode())
+
+	m.ZoomIn()
+	nodes := testNodes()
+	nodes[1].Children = nodes[1].Children[:2]
+	m.SetNodes(nodes)
+	assert.Nil(t, m.ZoomRoot())
+	assert.False(t, m.ZoomOut())
+}
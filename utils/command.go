@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CommandWatcher runs a command on an interval, parsing its output each time.
+type CommandWatcher struct {
+	args     []string
+	interval time.Duration
+	format   Format
+	// started is set once the command has run, as the first run is not delayed
+	started bool
+}
+
+// NewCommandWatcher creates a watcher running the command args every interval.
+// A single argument is run with sh, so may contain pipes and quoting, while
+// several are run directly as the program and its arguments. The output is
+// parsed in the given format, or detected if the format is empty.
+func NewCommandWatcher(args []string, interval time.Duration, format Format) *CommandWatcher {
+	return &CommandWatcher{args: args, interval: interval, format: format}
+}
+
+// Command returns the command being run, quoting arguments as sh would need.
+func (w *CommandWatcher) Command() string {
+	if len(w.args) == 1 {
+		return w.args[0]
+	}
+	quoted := make([]string, 0, len(w.args))
+	for _, arg := range w.args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Interval returns the time between runs of the command.
+func (w *CommandWatcher) Interval() time.Duration {
+	return w.interval
+}
+
+// Next returns a command which waits for the interval, runs the command
+// and sends a ReloadMsg with its parsed output.
+func (w *CommandWatcher) Next() tea.Cmd {
+	delay := w.interval
+	if !w.started {
+		delay = 0
+		w.started = true
+	}
+	return func() tea.Msg {
+		time.Sleep(delay)
+		return w.run()
+	}
+}
+
+// run runs the command and parses its output.
+func (w *CommandWatcher) run() ReloadMsg {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", w.args[0])
+	if len(w.args) > 1 {
+		cmd = exec.Command(w.args[0], w.args[1:]...)
+	}
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		// the last line written to stderr usually says what went wrong
+		var exit *exec.ExitError
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if errors.As(err, &exit) && lines[len(lines)-1] != "" {
+			err = fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+		}
+		return ReloadMsg{Err: err}
+	}
+	doc, err := Load(stdout.Bytes(), w.format)
+	if err != nil {
+		return ReloadMsg{Err: fmt.Errorf("parsing output: %w", err)}
+	}
+	return ReloadMsg{Doc: doc, Content: stdout.Bytes()}
+}
+
+// unquoted matches arguments which sh reads as they are written.
+var unquoted = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes arg for sh if it contains any special characters.
+func shellQuote(arg string) string {
+	if unquoted.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
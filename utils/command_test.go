@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandWatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "json",
+			args: []string{`echo '{"a": 1, "b": 2}'`},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "arguments are not split again",
+			args: []string{"printf", "%s", `{"a b": 1, "it's": 2}`},
+			want: []string{"a b", "it's"},
+		},
+		{
+			name:    "failure",
+			args:    []string{"echo 'not found' >&2; exit 3"},
+			wantErr: "exit status 3: not found",
+		},
+		{
+			name:    "invalid output",
+			args:    []string{`echo '{"a": '`},
+			wantErr: "parsing output",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewCommandWatcher(tt.args, time.Hour, "")
+			// the first run is not delayed by the interval
+			msg := w.Next()().(ReloadMsg)
+			if tt.wantErr != "" {
+				if msg.Err == nil || !strings.Contains(msg.Err.Error(), tt.wantErr) {
+					t.Errorf("ReloadMsg error = %v, want %q", msg.Err, tt.wantErr)
+				}
+				return
+			}
+			if msg.Err != nil {
+				t.Fatalf("ReloadMsg error = %v", msg.Err)
+			}
+			if got := nodeValues(msg.Doc.Nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReloadMsg nodes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandWatcherCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"kubectl get pods | jq .items"}, "kubectl get pods | jq .items"},
+		{[]string{"curl", "-s", "https://example.com/api?a=1"}, "curl -s 'https://example.com/api?a=1'"},
+		{[]string{"printf", "%s", `{"it's": 1}`}, `printf %s '{"it'\''s": 1}'`},
+	}
+	for _, tt := range tests {
+		if got := NewCommandWatcher(tt.args, time.Second, "").Command(); got != tt.want {
+			t.Errorf("Command() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestHistory(t *testing.T) {
+	var h history
+	at := time.Now()
+	for i := range historySize + 2 {
+		if !h.add([]byte{byte(i)}, at) {
+			t.Fatalf("add(%d) = false, want true while the latest is shown", i)
+		}
+	}
+	if got := len(h.entries); got != historySize {
+		t.Errorf("len(entries) = %d, want %d", got, historySize)
+	}
+
+	entry, ok := h.step(-1)
+	if !ok || entry.content[0] != historySize {
+		t.Errorf("step(-1) = %v, %v, want entry %d", entry.content, ok, historySize)
+	}
+	// new entries are recorded without moving away from the older entry shown
+	if h.add([]byte{100}, at) {
+		t.Errorf("add() = true, want false while browsing")
+	}
+	if got := h.entries[h.current].content[0]; got != historySize {
+		t.Errorf("current entry = %d, want %d", got, historySize)
+	}
+	if _, ok := h.step(1); !ok {
+		t.Errorf("step(1) = false, want true")
+	}
+	if entry, ok := h.step(1); !ok || entry.content[0] != 100 || !h.latest() {
+		t.Errorf("step(1) = %v, %v, want the latest entry", entry.content, ok)
+	}
+	if _, ok := h.step(1); ok {
+		t.Errorf("step(1) past the latest = true, want false")
+	}
+	for range historySize {
+		h.step(-1)
+	}
+	if _, ok := h.step(-1); ok || h.current != 0 {
+		t.Errorf("step(-1) past the oldest = true, want false")
+	}
+
+	// the oldest entry is kept while it is shown, dropping the next instead
+	oldest, next := h.entries[0].content[0], h.entries[3].content[0]
+	h.add([]byte{101}, at)
+	h.add([]byte{102}, at)
+	if got := h.entries[h.current].content[0]; h.current != 0 || got != oldest {
+		t.Errorf("current entry = %d at %d, want %d at 0", got, h.current, oldest)
+	}
+	if got := h.entries[1].content[0]; len(h.entries) != historySize || got != next {
+		t.Errorf("entries[1] = %d of %d, want %d of %d", got, len(h.entries), next, historySize)
+	}
+}
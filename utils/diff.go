@@ -0,0 +1,374 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// DiffDocuments compares two documents, returning the nodes of a tree showing
+// the new document with the changes from the old one marked. The elements of
+// arrays are matched by the value of key when they are objects which have it,
+// and by their contents otherwise.
+func DiffDocuments(old, new *Document, key string) []*tree.Node {
+	var root *tree.Node
+	if len(old.Values) == 1 && len(new.Values) == 1 {
+		root = Diff(old.Values[0], new.Values[0], key)
+	} else {
+		// streams of documents are compared as arrays of their documents
+		root = Diff(old.Values, new.Values, key)
+	}
+	// a root which changed type is shown itself, so that the change is marked
+	if len(root.Children) == 0 || root.Change != tree.ChangeNone {
+		root.Value = "value"
+		root.Synthetic = true
+		root.Expand = true
+		return []*tree.Node{root}
+	}
+	return root.Children
+}
+
+// Diff compares the values old and new, returning a node for new with the
+// changes from old marked on it and its descendants.
+func Diff(old, new any, key string) *tree.Node {
+	d := differ{key: key}
+	return d.diff(old, new)
+}
+
+// differ compares values, matching objects in arrays by the value of key.
+type differ struct {
+	key string
+}
+
+// diff returns the node for new, marked with the changes from old.
+func (d differ) diff(old, new any) *tree.Node {
+	oldKeys, oldValues, oldObject := objectEntries(old)
+	newKeys, newValues, newObject := objectEntries(new)
+	oldItems, oldArray := old.([]any)
+	newItems, newArray := new.([]any)
+	var children []*tree.Node
+	switch {
+	case oldObject && newObject:
+		children = d.diffObjects(oldKeys, oldValues, newKeys, newValues)
+	case oldArray && newArray:
+		children = d.diffArrays(oldItems, newItems)
+	default:
+		node := getTypedEntry(new).Treeify()
+		if !equalValues(old, new) {
+			node.Change = tree.ChangeChanged
+			node.Desc = getTypedEntry(old).String() + " → " + node.Desc
+		}
+		return node
+	}
+	node := getTypedEntry(new).Treeify()
+	node.Children = children
+	// expand the nodes down to each change, leaving the rest collapsed
+	for _, child := range children {
+		if hasChanges(child) {
+			node.Expand = true
+		}
+	}
+	return node
+}
+
+// diffObjects returns the children of an object compared by key. Removed keys
+// are placed after the key which preceded them in the old object.
+func (d differ) diffObjects(oldKeys []string, oldValues map[string]any, newKeys []string, newValues map[string]any) []*tree.Node {
+	oldIndex := make(map[string]int, len(oldKeys))
+	for i, k := range oldKeys {
+		oldIndex[k] = i
+	}
+	children := make([]*tree.Node, 0, len(newKeys))
+	// removedFrom adds the run of removed keys starting at old position start
+	removedFrom := func(start int) {
+		for i := start; i < len(oldKeys); i++ {
+			k := oldKeys[i]
+			if _, ok := newValues[k]; ok {
+				return
+			}
+			children = append(children, markAll(keyed(getTypedEntry(oldValues[k]).Treeify(), k), tree.ChangeRemoved))
+		}
+	}
+	removedFrom(0)
+	for _, k := range newKeys {
+		i, ok := oldIndex[k]
+		if !ok {
+			children = append(children, markAll(keyed(getTypedEntry(newValues[k]).Treeify(), k), tree.ChangeAdded))
+			continue
+		}
+		children = append(children, keyed(d.diff(oldValues[k], newValues[k]), k))
+		removedFrom(i + 1)
+	}
+	return children
+}
+
+// diffArrays returns the children of an array, pairing the elements of old
+// and new. Paired elements out of order relative to the others are marked as
+// moved, and removed elements are placed after the element which preceded
+// them in old.
+func (d differ) diffArrays(old, new []any) []*tree.Node {
+	pairs := d.pairElements(old, new)
+	used := make([]bool, len(old))
+	paired := make([]int, 0, len(new))
+	for _, i := range pairs {
+		if i >= 0 {
+			used[i] = true
+			paired = append(paired, i)
+		}
+	}
+	// the paired elements which keep their order are those in the longest
+	// increasing run of old positions, and the rest have moved
+	inOrder := make(map[int]bool)
+	for _, i := range longestIncreasing(paired) {
+		inOrder[i] = true
+	}
+
+	children := make([]*tree.Node, 0, len(new))
+	removed := make([]bool, len(old))
+	// removedFrom adds the run of removed elements starting at old position start
+	removedFrom := func(start int) {
+		for i := start; i < len(old) && !used[i] && !removed[i]; i++ {
+			children = append(children, markAll(indexed(getTypedEntry(old[i]).Treeify(), i), tree.ChangeRemoved))
+			removed[i] = true
+		}
+	}
+	removedFrom(0)
+	for j, i := range pairs {
+		if i < 0 {
+			children = append(children, markAll(indexed(getTypedEntry(new[j]).Treeify(), j), tree.ChangeAdded))
+			continue
+		}
+		node := indexed(d.diff(old[i], new[j]), j)
+		if !inOrder[i] {
+			node.Change = tree.ChangeMoved
+			node.Desc = "from " + strconv.Itoa(i) + ": " + node.Desc
+		}
+		children = append(children, node)
+		if inOrder[i] {
+			removedFrom(i + 1)
+		}
+	}
+	// elements which followed a moved element are left for the end
+	for i := range old {
+		removedFrom(i)
+	}
+	return children
+}
+
+// pairElements returns the position in old of the element paired with each
+// element of new, or -1 if it was added. Objects with the key are paired by its
+// value, then equal elements are paired in order, and any left over which are
+// at the same position and of the same type are paired as changed.
+func (d differ) pairElements(old, new []any) []int {
+	pairs := make([]int, len(new))
+	for j := range pairs {
+		pairs[j] = -1
+	}
+	used := make([]bool, len(old))
+	byKey := make(map[string][]int)
+	byValue := make(map[string][]int)
+	for i, item := range old {
+		if k, ok := d.keyOf(item); ok {
+			byKey[k] = append(byKey[k], i)
+		} else {
+			byValue[canonical(item)] = append(byValue[canonical(item)], i)
+		}
+	}
+	take := func(index map[string][]int, k string) int {
+		if candidates := index[k]; len(candidates) > 0 {
+			index[k] = candidates[1:]
+			return candidates[0]
+		}
+		return -1
+	}
+	for j, item := range new {
+		if k, ok := d.keyOf(item); ok {
+			pairs[j] = take(byKey, k)
+		} else {
+			pairs[j] = take(byValue, canonical(item))
+		}
+		if pairs[j] >= 0 {
+			used[pairs[j]] = true
+		}
+	}
+	for j, item := range new {
+		if pairs[j] < 0 && j < len(old) && !used[j] && sameKind(old[j], item) {
+			if _, ok := d.keyOf(item); !ok {
+				pairs[j] = j
+				used[j] = true
+			}
+		}
+	}
+	return pairs
+}
+
+// keyOf returns the canonical form of the matching key of an object.
+func (d differ) keyOf(item any) (string, bool) {
+	if d.key == "" {
+		return "", false
+	}
+	_, values, ok := objectEntries(item)
+	if !ok {
+		return "", false
+	}
+	v, ok := values[d.key]
+	if !ok {
+		return "", false
+	}
+	return canonical(v), true
+}
+
+// longestIncreasing returns a longest strictly increasing subsequence of values.
+func longestIncreasing(values []int) []int {
+	// tails[k] is the index of the smallest last value of a run of length k+1
+	tails := make([]int, 0)
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+	run := make([]int, len(tails))
+	for k, i := len(tails)-1, -1; k >= 0; k-- {
+		if i < 0 {
+			i = tails[len(tails)-1]
+		} else {
+			i = prev[i]
+		}
+		run[k] = values[i]
+	}
+	return run
+}
+
+// keyed names node as the value of key k in an object.
+func keyed(node *tree.Node, k string) *tree.Node {
+	node.Value = k
+	return node
+}
+
+// indexed names node as the element at index i in an array.
+func indexed(node *tree.Node, i int) *tree.Node {
+	node.Value = strconv.Itoa(i)
+	node.InArray = true
+	return node
+}
+
+// markAll sets the change of node and all of its descendants.
+func markAll(node *tree.Node, change tree.Change) *tree.Node {
+	node.Change = change
+	for _, child := range node.Children {
+		markAll(child, change)
+	}
+	return node
+}
+
+// hasChanges returns true if node or any of its descendants has changed.
+func hasChanges(node *tree.Node) bool {
+	if node.Change != tree.ChangeNone {
+		return true
+	}
+	for _, child := range node.Children {
+		if hasChanges(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameKind returns true if a and b are both objects, both arrays or both scalars.
+func sameKind(a, b any) bool {
+	kind := func(v any) int {
+		if _, _, ok := objectEntries(v); ok {
+			return 1
+		}
+		if _, ok := v.([]any); ok {
+			return 2
+		}
+		return 0
+	}
+	return kind(a) == kind(b)
+}
+
+// equalValues returns true if a and b hold the same data, ignoring the order
+// of object keys and how numbers are written.
+func equalValues(a, b any) bool {
+	return canonical(a) == canonical(b)
+}
+
+// canonical returns a string which is the same for equal values.
+func canonical(v any) string {
+	var b strings.Builder
+	writeCanonical(&b, v)
+	return b.String()
+}
+
+func writeCanonical(b *strings.Builder, v any) {
+	if keys, values, ok := objectEntries(v); ok {
+		keys = append([]string(nil), keys...)
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for _, k := range keys {
+			b.WriteString(strconv.Quote(k))
+			b.WriteByte(':')
+			writeCanonical(b, values[k])
+			b.WriteByte(',')
+		}
+		b.WriteByte('}')
+		return
+	}
+	switch v := v.(type) {
+	case []any:
+		b.WriteByte('[')
+		for _, item := range v {
+			writeCanonical(b, item)
+			b.WriteByte(',')
+		}
+		b.WriteByte(']')
+	case json.Number:
+		b.WriteString(canonicalNumber(v.String()))
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		b.WriteString(canonicalNumber(strconv.FormatFloat(v, 'g', -1, 64)))
+	case string:
+		b.WriteString(strconv.Quote(v))
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case nil:
+		b.WriteString("null")
+	default:
+		out, _ := json.Marshal(v)
+		b.Write(out)
+	}
+}
+
+// canonicalNumber formats the number s so that equal numbers written
+// differently, such as 1 and 1.0, are the same. Integers are kept as written so
+// that large ones do not lose precision.
+func canonicalNumber(s string) string {
+	if !strings.ContainsAny(s, ".eE") {
+		return s
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// diffLines describes each node of a diff as its marker, indented key and description.
+func diffLines(nodes []*tree.Node, indent string) []string {
+	lines := make([]string, 0)
+	for _, node := range nodes {
+		lines = append(lines, node.Change.Marker()+" "+indent+node.Value+" "+node.Desc)
+		lines = append(lines, diffLines(node.Children, indent+"  ")...)
+	}
+	return lines
+}
+
+func mustLoad(t *testing.T, content string) *Document {
+	t.Helper()
+	doc, err := Load([]byte(content), "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return doc
+}
+
+func TestDiffDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		key  string
+		want []string
+	}{
+		{
+			name: "objects",
+			old:  `{"name": "api", "version": 1, "old": true, "zone": "eu"}`,
+			new:  `{"name": "api", "version": 2, "zone": "eu", "new": null}`,
+			want: []string{"  name api", "~ version 1 → 2", "- old true", "  zone eu", "+ new null"},
+		},
+		{
+			name: "numbers and key order",
+			old:  `{"a": {"x": 1.0, "y": [1, 2]}}`,
+			new:  "a:\n  y: [1, 2]\n  x: 1\n",
+			want: []string{"  a 1 1 2", "    y 1 2", "      0 1", "      1 2", "    x 1"},
+		},
+		{
+			name: "arrays",
+			old:  `["x", "y", "z", "gone", "gone too"]`,
+			new:  `["y", "x", "z", "w"]`,
+			want: []string{"↕ 0 from 1: y", "  1 x", "  2 z", "~ 3 gone → w", "- 4 gone too"},
+		},
+		{
+			name: "changed element at the same position",
+			old:  `[{"a": 1}, 5]`,
+			new:  `[{"a": 2}, 5]`,
+			want: []string{"  0 2", "~   a 1 → 2", "  1 5"},
+		},
+		{
+			name: "keyed",
+			old:  `[{"id": "a", "v": 1}, {"id": "b", "v": 1}, {"id": "c", "v": 1}]`,
+			new:  `[{"id": "c", "v": 1}, {"id": "a", "v": 2}, {"id": "d", "v": 1}]`,
+			key:  "id",
+			want: []string{
+				"↕ 0 from 2: c 1",
+				"    id c",
+				"    v 1",
+				"  1 a 2",
+				"    id a",
+				"~   v 1 → 2",
+				"- 1 b 1",
+				"-   id b",
+				"-   v 1",
+				"+ 2 d 1",
+				"+   id d",
+				"+   v 1",
+			},
+		},
+		{
+			name: "scalar",
+			old:  `1`,
+			new:  `"1"`,
+			want: []string{"~ value 1 → 1"},
+		},
+		{
+			name: "root type",
+			old:  `{"a": 1}`,
+			new:  `[1]`,
+			want: []string{"~ value 1 → 1", "    0 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffLines(DiffDocuments(mustLoad(t, tt.old), mustLoad(t, tt.new), tt.key), "")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffDocuments() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDiffExpand(t *testing.T) {
+	nodes := DiffDocuments(
+		mustLoad(t, `{"same": {"a": 1}, "deep": {"b": {"c": 1}}, "gone": {"d": 1}}`),
+		mustLoad(t, `{"same": {"a": 1}, "deep": {"b": {"c": 2}}}`),
+		"",
+	)
+	expanded := make([]string, 0)
+	var walk func(nodes []*tree.Node)
+	walk = func(nodes []*tree.Node) {
+		for _, node := range nodes {
+			if node.Expand {
+				expanded = append(expanded, node.Value)
+			}
+			walk(node.Children)
+		}
+	}
+	walk(nodes)
+	if want := []string{"deep", "b"}; !reflect.DeepEqual(expanded, want) {
+		t.Errorf("DiffDocuments() expanded = %v, want %v", expanded, want)
+	}
+}
+
+func TestLongestIncreasing(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   []int
+	}{
+		{nil, []int{}},
+		{[]int{0, 1, 2}, []int{0, 1, 2}},
+		{[]int{2, 0, 1}, []int{0, 1}},
+		{[]int{3, 1, 4, 0, 5, 2}, []int{1, 4, 5}},
+	}
+	for _, tt := range tests {
+		if got := longestIncreasing(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("longestIncreasing(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
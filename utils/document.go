@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// byteOrderMark may prefix UTF-8 files written on Windows
+var byteOrderMark = []byte("\xef\xbb\xbf")
+
+// Format is an input format which may be loaded into a Document.
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatYAML   Format = "yaml"
+)
+
+// ParseFormat returns the format with the given name. An empty name means the
+// format should be detected.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case "", FormatJSON, FormatNDJSON, FormatYAML:
+		return format, nil
+	case "jsonl":
+		return FormatNDJSON, nil
+	case "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected json, ndjson or yaml", name)
+}
+
+// FormatFromPath returns the format indicated by the extension of path, or an
+// empty format if the extension is not recognised.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return ""
+}
+
+// DetectFormat guesses the format of content. Anything which is not JSON or
+// NDJSON is assumed to be YAML, which is a superset of JSON. Scalars are checked
+// for JSON too, as YAML would lose the precision of large numbers.
+func DetectFormat(content []byte) Format {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, byteOrderMark))
+	if json.Valid(trimmed) {
+		return FormatJSON
+	}
+	if isNDJSON(trimmed) {
+		return FormatNDJSON
+	}
+	return FormatYAML
+}
+
+// Document is a loaded input, holding the root value of each document it
+// contains along with the nodes used to display them.
+type Document struct {
+	Values []any
+	Nodes  []*tree.Node
+}
+
+// Load parses content in the given format, detecting it if the format is empty.
+func Load(content []byte, format Format) (*Document, error) {
+	content = bytes.TrimPrefix(content, byteOrderMark)
+	if format == "" {
+		format = DetectFormat(content)
+	}
+	switch format {
+	case FormatNDJSON:
+		return LoadNDJSON(content, "")
+	case FormatYAML:
+		return LoadYaml(content)
+	case FormatJSON:
+		return LoadJson(content)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Query evaluates the jq style expression source against each document in turn.
+func (d *Document) Query(source string) ([]any, error) {
+	q, err := ParseQuery(source)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]any, 0)
+	for _, value := range d.Values {
+		values, err := q.Eval(value)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, values...)
+	}
+	return results, nil
+}
+
+// QueryNodes evaluates source against the document and converts the results to
+// tree nodes. An empty source returns the original nodes. The paths of the
+// nodes are relative to the query results rather than the document.
+func (d *Document) QueryNodes(source string) ([]*tree.Node, error) {
+	if strings.TrimSpace(source) == "" {
+		return d.Nodes, nil
+	}
+	results, err := d.Query(source)
+	if err != nil {
+		return nil, err
+	}
+	return treeifyResults(results), nil
+}
+
+// Treeify creates a tree model displaying the document.
+func (d *Document) Treeify() *tree.Model {
+	model := tree.New(d.Nodes, 1, 1)
+	model.Query = d.QueryNodes
+	return model
+}
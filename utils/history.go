@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// historySize is the number of outputs of a watched command which are kept
+const historySize = 20
+
+// historyKeys step through the outputs of a watched command.
+var historyKeys = struct {
+	Older key.Binding
+	Newer key.Binding
+}{
+	Older: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "older"),
+	),
+	Newer: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "newer"),
+	),
+}
+
+type historyEntry struct {
+	content []byte
+	time    time.Time
+}
+
+// history holds the recent outputs of a watched command. While an older
+// output is shown, new outputs are recorded without replacing it, and it is
+// kept when older entries are dropped.
+type history struct {
+	entries []historyEntry
+	// current is the index of the entry shown
+	current int
+}
+
+// add records content, returning true if it should be shown because the
+// latest entry was being shown.
+func (h *history) add(content []byte, at time.Time) bool {
+	latest := h.latest()
+	h.entries = append(h.entries, historyEntry{content: content, time: at})
+	if len(h.entries) > historySize {
+		// drop the oldest entry, unless it is the one shown
+		drop := 0
+		if !latest && h.current == 0 {
+			drop = 1
+		}
+		h.entries = slices.Delete(h.entries, drop, drop+1)
+		if drop < h.current {
+			h.current--
+		}
+	}
+	if latest {
+		h.current = len(h.entries) - 1
+	}
+	return latest
+}
+
+// latest returns true if the newest entry is shown, or there are none.
+func (h *history) latest() bool {
+	return h.current >= len(h.entries)-1
+}
+
+// step moves delta entries towards the newest, returning the entry to show or
+// false if there is none in that direction.
+func (h *history) step(delta int) (historyEntry, bool) {
+	i := h.current + delta
+	if i < 0 || i >= len(h.entries) {
+		return historyEntry{}, false
+	}
+	h.current = i
+	return h.entries[i], true
+}
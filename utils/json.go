@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
@@ -22,8 +26,28 @@ const (
 	entryTypeBoolean
 	entryTypeArray
 	entryTypeMap
+	entryTypeNull
 )
 
+// valueType returns the type used to style nodes holding entries of type t.
+func (t EntryType) valueType() tree.ValueType {
+	switch t {
+	case entryTypeString:
+		return tree.TypeString
+	case entryTypeInt, entryTypeFloat:
+		return tree.TypeNumber
+	case entryTypeBoolean:
+		return tree.TypeBoolean
+	case entryTypeNull:
+		return tree.TypeNull
+	case entryTypeArray:
+		return tree.TypeArray
+	case entryTypeMap:
+		return tree.TypeObject
+	}
+	return tree.TypeUnknown
+}
+
 type JsonBlob map[string]any
 
 func (d JsonBlob) Get(k string) TypedEntry {
@@ -37,7 +61,7 @@ func (d JsonBlob) Get(k string) TypedEntry {
 func (d JsonBlob) String() string {
 	ret := strings.Builder{}
 	first := true
-	for k := range d {
+	for _, k := range sortedKeys(d) {
 		if ret.Len() > MaxStringLength {
 			break
 		}
@@ -50,8 +74,8 @@ func (d JsonBlob) String() string {
 
 func (d JsonBlob) Treeify() *tree.Model {
 	nodes := make([]*tree.Node, 0)
-	for k, v := range d {
-		node := getTypedEntry(v).Treeify()
+	for _, k := range sortedKeys(d) {
+		node := getTypedEntry(d[k]).Treeify()
 		node.Value = k
 		node.Expand = true
 		nodes = append(nodes, node)
@@ -67,17 +91,25 @@ type TypedEntry struct {
 
 func getTypedEntry(v any) TypedEntry {
 	switch v := v.(type) {
+	case nil:
+		return TypedEntry{Type: entryTypeNull}
 	case string:
 		return TypedEntry{Type: entryTypeString, Value: v}
+	case json.Number:
+		// numbers are kept as decoded so large integers do not lose precision
+		if strings.ContainsAny(v.String(), ".eE") {
+			return TypedEntry{Type: entryTypeFloat, Value: v}
+		}
+		return TypedEntry{Type: entryTypeInt, Value: v}
 	case float64:
 		return TypedEntry{Type: entryTypeFloat, Value: v}
-	case int:
+	case int, int64:
 		return TypedEntry{Type: entryTypeInt, Value: v}
 	case bool:
 		return TypedEntry{Type: entryTypeBoolean, Value: v}
 	case []any:
 		return TypedEntry{Type: entryTypeArray, Value: v}
-	case map[string]any:
+	case map[string]any, *Object:
 		return TypedEntry{Type: entryTypeMap, Value: v}
 	default:
 		return TypedEntry{}
@@ -103,19 +135,33 @@ func (e TypedEntry) String() string {
 			first = false
 		case entryTypeInt:
 			ret.WriteString(spacerToken(first))
-			ret.WriteString(fmt.Sprintf("%d", entry.Value))
+			if n, ok := entry.Value.(json.Number); ok {
+				ret.WriteString(n.String())
+			} else {
+				ret.WriteString(fmt.Sprintf("%d", entry.Value))
+			}
 			first = false
 		case entryTypeFloat:
 			ret.WriteString(spacerToken(first))
-			ret.WriteString(fmt.Sprintf("%.3f", entry.Value))
+			if n, ok := entry.Value.(json.Number); ok {
+				f, _ := n.Float64()
+				ret.WriteString(fmt.Sprintf("%.3f", f))
+			} else {
+				ret.WriteString(fmt.Sprintf("%.3f", entry.Value))
+			}
+			first = false
+		case entryTypeNull:
+			ret.WriteString(spacerToken(first))
+			ret.WriteString("null")
 			first = false
 		case entryTypeArray:
 			for _, item := range entry.Value.([]interface{}) {
 				stack = stack.Push(getTypedEntry(item))
 			}
 		case entryTypeMap:
-			for _, v := range entry.Value.(map[string]interface{}) {
-				stack = stack.Push(getTypedEntry(v))
+			keys, values, _ := objectEntries(entry.Value)
+			for _, k := range keys {
+				stack = stack.Push(getTypedEntry(values[k]))
 			}
 		default:
 			return ""
@@ -129,17 +175,21 @@ func (e TypedEntry) Treeify() *tree.Node {
 		Desc:     e.String(),
 		Expand:   false,
 		Children: make([]*tree.Node, 0),
+		Data:     e.Value,
+		Type:     e.Type.valueType(),
 	}
 	switch e.Type {
 	case entryTypeArray:
 		for i, item := range e.Value.([]interface{}) {
 			child := getTypedEntry(item).Treeify()
 			child.Value = strconv.FormatUint(uint64(i), 10)
+			child.InArray = true
 			node.Children = append(node.Children, child)
 		}
 	case entryTypeMap:
-		for k, v := range e.Value.(map[string]interface{}) {
-			child := getTypedEntry(v).Treeify()
+		keys, values, _ := objectEntries(e.Value)
+		for _, k := range keys {
+			child := getTypedEntry(values[k]).Treeify()
 			child.Value = k
 			node.Children = append(node.Children, child)
 		}
@@ -154,3 +204,21 @@ func spacerToken(first bool) string {
 	}
 	return " "
 }
+
+// LoadJson parses a JSON document with any value at its root. Objects and
+// arrays are displayed with their children as the top level nodes.
+func LoadJson(content []byte) (*Document, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	value, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unexpected data after top level value at offset %d", dec.InputOffset())
+	}
+	return &Document{
+		Values: []any{value},
+		Nodes:  treeifyResults([]any{value}),
+	}, nil
+}
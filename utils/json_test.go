@@ -1,11 +1,12 @@
 package utils
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
 
-	tree "github.com/savannahostrowski/tree-bubble"
+	"github.com/crosleyzack/bubbles/tree"
 )
 
 func TestGetTypedEntry(t *testing.T) {
@@ -29,6 +30,21 @@ func TestGetTypedEntry(t *testing.T) {
 			v:    3.14,
 			want: TypedEntry{Type: entryTypeFloat, Value: 3.14},
 		},
+		{
+			name: "number int",
+			v:    json.Number("12345678901234567890"),
+			want: TypedEntry{Type: entryTypeInt, Value: json.Number("12345678901234567890")},
+		},
+		{
+			name: "number float",
+			v:    json.Number("1e3"),
+			want: TypedEntry{Type: entryTypeFloat, Value: json.Number("1e3")},
+		},
+		{
+			name: "null",
+			v:    nil,
+			want: TypedEntry{Type: entryTypeNull},
+		},
 		{
 			name: "boolean",
 			v:    true,
@@ -75,6 +91,21 @@ func TestTypedEntryString(t *testing.T) {
 			e:    TypedEntry{Type: entryTypeFloat, Value: 3.14},
 			want: "3.140",
 		},
+		{
+			name: "number int",
+			e:    TypedEntry{Type: entryTypeInt, Value: json.Number("12345678901234567890")},
+			want: "12345678901234567890",
+		},
+		{
+			name: "number float",
+			e:    TypedEntry{Type: entryTypeFloat, Value: json.Number("2.5")},
+			want: "2.500",
+		},
+		{
+			name: "null",
+			e:    TypedEntry{Type: entryTypeNull},
+			want: "null",
+		},
 		{
 			name: "boolean",
 			e:    TypedEntry{Type: entryTypeBoolean, Value: true},
@@ -85,10 +116,14 @@ func TestTypedEntryString(t *testing.T) {
 			e:    TypedEntry{Type: entryTypeArray, Value: []interface{}{"a", 1, "c"}},
 			want: "a 1 c",
 		},
+		{
+			name: "array with null",
+			e:    TypedEntry{Type: entryTypeArray, Value: []interface{}{"a", nil}},
+			want: "a null",
+		},
 		{
 			name: "map",
 			e:    TypedEntry{Type: entryTypeMap, Value: map[string]interface{}{"key": "value", "number": 123, "nested": map[string]interface{}{"nestedKey": "nestedValue"}}},
-			// TODO flakey
 			want: "value 123 nestedValue",
 		},
 	}
@@ -105,94 +140,128 @@ func TestTypedEntryTreeify(t *testing.T) {
 	tests := []struct {
 		name string
 		e    TypedEntry
-		want tree.Node
+		want *tree.Node
 	}{
 		{
 			name: "string",
 			e:    TypedEntry{Type: entryTypeString, Value: "hello"},
-			want: tree.Node{
+			want: &tree.Node{
 				Value:    "",
 				Desc:     "hello",
-				Children: []tree.Node{},
+				Children: []*tree.Node{},
+				Data:     "hello",
+				Type:     tree.TypeString,
 			},
 		},
 		{
 			name: "int",
 			e:    TypedEntry{Type: entryTypeInt, Value: 42},
-			want: tree.Node{
+			want: &tree.Node{
 				Value:    "",
 				Desc:     "42",
-				Children: []tree.Node{},
+				Children: []*tree.Node{},
+				Data:     42,
+				Type:     tree.TypeNumber,
 			},
 		},
 		{
 			name: "float",
 			e:    TypedEntry{Type: entryTypeFloat, Value: 3.14},
-			want: tree.Node{
+			want: &tree.Node{
 				Value:    "",
 				Desc:     "3.140",
-				Children: []tree.Node{},
+				Children: []*tree.Node{},
+				Data:     3.14,
+				Type:     tree.TypeNumber,
 			},
 		},
 		{
 			name: "boolean",
 			e:    TypedEntry{Type: entryTypeBoolean, Value: true},
-			want: tree.Node{
+			want: &tree.Node{
 				Value:    "",
 				Desc:     "true",
-				Children: []tree.Node{},
+				Children: []*tree.Node{},
+				Data:     true,
+				Type:     tree.TypeBoolean,
+			},
+		},
+		{
+			name: "array",
+			e:    TypedEntry{Type: entryTypeArray, Value: []any{"a", 1, "c"}},
+			want: &tree.Node{
+				Value: "",
+				Desc:  "a 1 c",
+				Children: []*tree.Node{
+					{
+						Value:    "0",
+						Desc:     "a",
+						Children: []*tree.Node{},
+						InArray:  true,
+						Data:     "a",
+						Type:     tree.TypeString,
+					},
+					{
+						Value:    "1",
+						Desc:     "1",
+						Children: []*tree.Node{},
+						InArray:  true,
+						Data:     1,
+						Type:     tree.TypeNumber,
+					},
+					{
+						Value:    "2",
+						Desc:     "c",
+						Children: []*tree.Node{},
+						InArray:  true,
+						Data:     "c",
+						Type:     tree.TypeString,
+					},
+				},
+				Data: []any{"a", 1, "c"},
+				Type: tree.TypeArray,
+			},
+		},
+		{
+			name: "map",
+			e:    TypedEntry{Type: entryTypeMap, Value: object("key", "value", "number", 123, "nested", object("nestedKey", "nestedValue"))},
+			want: &tree.Node{
+				Desc: "value 123 nestedValue",
+				Children: []*tree.Node{
+					{
+						Value:    "key",
+						Desc:     "value",
+						Children: []*tree.Node{},
+						Data:     "value",
+						Type:     tree.TypeString,
+					},
+					{
+						Value:    "number",
+						Desc:     "123",
+						Children: []*tree.Node{},
+						Data:     123,
+						Type:     tree.TypeNumber,
+					},
+					{
+						Value: "nested",
+						Desc:  "nestedValue",
+						Children: []*tree.Node{
+							{
+								Value:    "nestedKey",
+								Desc:     "nestedValue",
+								Children: []*tree.Node{},
+								Data:     "nestedValue",
+								Type:     tree.TypeString,
+							},
+						},
+						Data: object("nestedKey", "nestedValue"),
+						Type: tree.TypeObject,
+					},
+				},
+				Data: object("key", "value", "number", 123, "nested", object("nestedKey", "nestedValue")),
+				Type: tree.TypeObject,
 			},
 		},
-		// TODO Debug
-		// {
-		// 	name: "array",
-		// 	e:    typedEntry{Type: entryTypeArray, Value: []any{"a", 1, "c"}},
-		// 	want: tree.Node{
-		// 		Value: "",
-		// 		Desc:  "a 1 c",
-		// 		Children: []tree.Node{
-		// 			{
-		// 				Value: "0",
-		// 				Desc:  "a",
-		// 			},
-		// 			{
-		// 				Value: "1",
-		// 				Desc:  "1",
-		// 			},
-		// 			{
-		// 				Value: "2",
-		// 				Desc:  "c",
-		// 			},
-		// 		},
-		// 	},
-		// },
-		// {
-		// 	name: "map",
-		// 	e:    typedEntry{Type: entryTypeMap, Value: map[string]any{"key": "value", "number": 123, "nested": map[string]any{"nestedKey": "nestedValue"}}},
-		// 	want: tree.Node{
-		// 		Desc: "value 123 nestedValue",
-		// 		Children: []tree.Node{
-		// 			{
-		// 				Value: "key",
-		// 				Desc:  "value",
-		// 			},
-		// 			{
-		// 				Value: "number",
-		// 				Desc:  "123",
-		// 			},
-		// 			{
-		// 				Value: "nested",
-		// 				Desc:  "",
-		// 				Children: []tree.Node{
-		// 					{
-		// 						Value: "nestedKey",
-		// 						Desc:  "nestedValue",
-		// 					},
-		// 				},
-		// 			},
-		// 		},
-		// 	},
-		// },
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -281,13 +350,12 @@ func TestDictString(t *testing.T) {
 		{
 			name: "multiple key-value pairs",
 			d:    JsonBlob{"key1": "value1", "key2": 42, "key3": true},
-			// TODO flakey
 			want: "key1: value1 key2: 42 key3: true",
 		},
 		{
 			name: "nested map",
 			d:    JsonBlob{"nested": map[string]interface{}{"key": "value"}, "another": map[string]interface{}{"nested": "foo"}},
-			want: "nested: value another: foo",
+			want: "another: foo nested: value",
 		},
 		{
 			name: "array value",
@@ -308,3 +376,60 @@ func TestDictString(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadJson(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		values  []string
+		descs   []string
+	}{
+		{
+			name:    "object",
+			content: `{"id": 12345678901234567890}`,
+			values:  []string{"id"},
+			descs:   []string{"12345678901234567890"},
+		},
+		{
+			name:    "array",
+			content: `[{"id": 1}, null, "x"]`,
+			values:  []string{"0", "1", "2"},
+			descs:   []string{"1", "null", "x"},
+		},
+		{
+			name:    "scalar",
+			content: `42`,
+			values:  []string{"0"},
+			descs:   []string{"42"},
+		},
+		{
+			name:    "null",
+			content: `null`,
+			values:  []string{"0"},
+			descs:   []string{"null"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadJson([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("LoadJson() error = %v", err)
+			}
+			descs := make([]string, 0, len(doc.Nodes))
+			for _, node := range doc.Nodes {
+				descs = append(descs, node.Desc)
+			}
+			if got := nodeValues(doc.Nodes); !reflect.DeepEqual(got, tt.values) {
+				t.Errorf("LoadJson() values = %v, want %v", got, tt.values)
+			}
+			if !reflect.DeepEqual(descs, tt.descs) {
+				t.Errorf("LoadJson() descs = %v, want %v", descs, tt.descs)
+			}
+		})
+	}
+	for _, content := range []string{``, `{"a": 1} {"a": 2}`, `{"a":`} {
+		if _, err := LoadJson([]byte(content)); err == nil {
+			t.Errorf("LoadJson(%q) expected error", content)
+		}
+	}
+}
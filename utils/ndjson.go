@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// LoadNDJSON parses newline delimited JSON, with one top level node per line.
+// Nodes are labelled by the value of the label field, or by line number when
+// label is empty or the field is missing. Blank lines are skipped.
+func LoadNDJSON(content []byte, label string) (*Document, error) {
+	d := &Document{
+		Values: make([]any, 0),
+		Nodes:  make([]*tree.Node, 0),
+	}
+	r := NewNDJSONReader(bytes.NewReader(content), label)
+	for {
+		value, node, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			value, node, err = r.Flush()
+			if node == nil {
+				return d, err
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		d.Values = append(d.Values, value)
+		d.Nodes = append(d.Nodes, node)
+	}
+}
+
+// LineError is an error parsing a single line of NDJSON.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSONReader parses newline delimited JSON one line at a time, so the first
+// lines can be displayed before the rest of the input has been written.
+type NDJSONReader struct {
+	// Label is the field whose value labels each node
+	Label string
+
+	reader *bufio.Reader
+	line   int
+	// partial holds the start of a line which has not been terminated yet
+	partial []byte
+}
+
+// NewNDJSONReader creates a reader parsing lines from r.
+func NewNDJSONReader(r io.Reader, label string) *NDJSONReader {
+	return &NDJSONReader{
+		Label:  label,
+		reader: bufio.NewReader(r),
+	}
+}
+
+// Next returns the value of the next non blank line and the node displaying it.
+// It returns io.EOF when no complete line is available. An unterminated final
+// line is kept, so Next may be called again once more has been written.
+func (r *NDJSONReader) Next() (any, *tree.Node, error) {
+	for {
+		chunk, err := r.reader.ReadBytes('\n')
+		r.partial = append(r.partial, chunk...)
+		if err != nil {
+			return nil, nil, err
+		}
+		text := r.partial
+		r.partial = nil
+		r.line++
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		return r.parse(text)
+	}
+}
+
+// Flush parses an unterminated final line. It returns a nil node if there is none.
+func (r *NDJSONReader) Flush() (any, *tree.Node, error) {
+	text := r.partial
+	r.partial = nil
+	if len(bytes.TrimSpace(text)) == 0 {
+		return nil, nil, nil
+	}
+	r.line++
+	return r.parse(text)
+}
+
+// parse decodes a single line and creates its node.
+func (r *NDJSONReader) parse(text []byte) (any, *tree.Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(text))
+	dec.UseNumber()
+	value, err := decodeOrdered(dec)
+	if errors.Is(err, io.EOF) {
+		// the line ended part way through a value, which must not be mistaken for the end of the input
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, nil, &LineError{Line: r.line, Err: err}
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, nil, &LineError{Line: r.line, Err: errors.New("unexpected data after value")}
+	}
+	node := getTypedEntry(value).Treeify()
+	node.Value = r.label(value)
+	node.Synthetic = true
+	return value, node, nil
+}
+
+// label returns the value of the label field of value, falling back to the line number.
+func (r *NDJSONReader) label(value any) string {
+	if r.Label != "" {
+		if keys, values, ok := objectEntries(value); ok {
+			for _, k := range keys {
+				if k != r.Label {
+					continue
+				}
+				switch v := values[k].(type) {
+				case *Object, map[string]any, []any:
+				case nil:
+					return "null"
+				default:
+					return fmt.Sprint(v)
+				}
+			}
+		}
+	}
+	return strconv.Itoa(r.line)
+}
+
+// SniffNDJSON returns true if the input buffered by r starts with a line holding
+// a complete JSON value followed by another line, which a JSON document spread
+// over several lines never does. It reads only as much as it needs to decide,
+// without consuming any input, so that NDJSON can be streamed once detected.
+func SniffNDJSON(r *bufio.Reader) bool {
+	for {
+		// wait for at least one more read, rather than a fixed amount which a slow stream may never fill
+		_, err := r.Peek(r.Buffered() + 1)
+		buf, _ := r.Peek(r.Buffered())
+		buf = bytes.TrimLeft(bytes.TrimPrefix(buf, byteOrderMark), " \t\r\n")
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			if len(bytes.TrimSpace(buf[i+1:])) > 0 {
+				return json.Valid(buf[:i])
+			}
+			if !json.Valid(buf[:i]) {
+				return false
+			}
+		}
+		if err != nil || r.Buffered() == r.Size() {
+			return false
+		}
+	}
+}
+
+// isNDJSON returns true if content has multiple lines and every non blank line is valid JSON.
+func isNDJSON(content []byte) bool {
+	lines := 0
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return false
+		}
+		lines++
+	}
+	return lines > 1
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+func TestLoadNDJSON(t *testing.T) {
+	content := "{\"level\":\"info\",\"msg\":\"start\"}\n\n{\"level\":\"error\",\"msg\":\"fail\"}\n\"done\"\n"
+	doc, err := LoadNDJSON([]byte(content), "")
+	if err != nil {
+		t.Fatalf("LoadNDJSON() error = %v", err)
+	}
+	if got, want := nodeValues(doc.Nodes), []string{"1", "3", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadNDJSON() nodes = %v, want %v", got, want)
+	}
+	if got := doc.Nodes[2].Desc; got != "done" {
+		t.Errorf("LoadNDJSON() desc = %v, want done", got)
+	}
+	// the line numbers are not part of the paths of the values on each line
+	if got := doc.Nodes[0].Children[1].FormatPath(tree.PathJq); got != ".msg" {
+		t.Errorf("FormatPath() = %v, want .msg", got)
+	}
+	levels, err := doc.Query(".level?")
+	if err != nil {
+		t.Fatalf("Document.Query() error = %v", err)
+	}
+	if want := []any{"info", "error"}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("Document.Query() = %v, want %v", levels, want)
+	}
+
+	if _, err := LoadNDJSON([]byte("{}\n{\"a\":\n"), ""); err == nil || err.Error()[:7] != "line 2:" {
+		t.Errorf("LoadNDJSON() error = %v, want line 2 error", err)
+	}
+}
+
+func TestLoadNDJSONLabel(t *testing.T) {
+	content := "{\"ts\":\"10:00\",\"msg\":\"a\"}\n{\"msg\":\"b\"}\n{\"ts\":3}\n{\"ts\":{\"h\":1}}"
+	doc, err := LoadNDJSON([]byte(content), "ts")
+	if err != nil {
+		t.Fatalf("LoadNDJSON() error = %v", err)
+	}
+	if got, want := nodeValues(doc.Nodes), []string{"10:00", "2", "3", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadNDJSON() nodes = %v, want %v", got, want)
+	}
+}
+
+func TestNDJSONReaderPartialLine(t *testing.T) {
+	r, w := io.Pipe()
+	reader := NewNDJSONReader(r, "")
+	go func() {
+		w.Write([]byte("{\"a\": 1}\n{\"a\""))
+		w.Write([]byte(": 2}\n"))
+		w.Close()
+	}()
+	values := make([]any, 0)
+	for {
+		value, _, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		values = append(values, value)
+	}
+	if want := []any{object("a", json.Number("1")), object("a", json.Number("2"))}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Next() = %v, want %v", values, want)
+	}
+}
+
+func TestNDJSONStream(t *testing.T) {
+	stream := NewNDJSONStream(strings.NewReader("1\nx\n2\n3"), "", false)
+	nodes := make([]string, 0)
+	var lineErr error
+	for {
+		msg := stream.Next()().(NDJSONMsg)
+		nodes = append(nodes, nodeValues(msg.Nodes)...)
+		if msg.Err != nil {
+			lineErr = msg.Err
+		}
+		if msg.Done {
+			break
+		}
+	}
+	if want := []string{"1", "3", "4"}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("NDJSONStream nodes = %v, want %v", nodes, want)
+	}
+	if lineErr == nil || lineErr.Error()[:7] != "line 2:" {
+		t.Errorf("NDJSONStream error = %v, want line 2 error", lineErr)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Format
+	}{
+		{"object", `{"a": 1}`, FormatJSON},
+		{"pretty object", "{\n  \"a\": 1\n}\n", FormatJSON},
+		{"array", ` [1, 2]`, FormatJSON},
+		{"bom", "\xef\xbb\xbf{\"a\": 1}", FormatJSON},
+		{"ndjson", "{\"a\": 1}\n{\"a\": 2}\n", FormatNDJSON},
+		{"yaml", "a: 1\nb: [1, 2]\n", FormatYAML},
+		{"flow yaml", "{a: 1}", FormatYAML},
+		{"number", "12345678901234567890\n", FormatJSON},
+		{"string", `"yes"`, FormatJSON},
+		{"yaml scalar", "yes", FormatYAML},
+		{"ndjson scalars", "1\n2\n", FormatNDJSON},
+		{"ndjson strings", "\"a\"\n{\"b\": 1}\n", FormatNDJSON},
+		{"empty", "", FormatYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat([]byte(tt.content)); got != tt.want {
+				t.Errorf("DetectFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffNDJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"ndjson", "{\"a\": 1}\n{\"a\": 2}\n", true},
+		{"blank lines", "\n\n{\"a\": 1}\n\n{\"a\": 2}", true},
+		{"scalars", "1\n2\n", true},
+		{"single line", "{\"a\": 1}\n", false},
+		{"pretty object", "{\n  \"a\": 1\n}\n", false},
+		{"yaml", "a: 1\nb: 2\n", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a reader returning a byte at a time checks that partial lines are waited for
+			r := bufio.NewReader(iotest.OneByteReader(strings.NewReader(tt.content)))
+			if got := SniffNDJSON(r); got != tt.want {
+				t.Errorf("SniffNDJSON() = %v, want %v", got, tt.want)
+			}
+			if rest, _ := io.ReadAll(r); string(rest) != tt.content {
+				t.Errorf("SniffNDJSON() consumed input, left %q", rest)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Object is a decoded JSON or YAML object which remembers the order of its keys.
+type Object struct {
+	Keys   []string
+	Values map[string]any
+}
+
+// NewObject creates an empty object.
+func NewObject() *Object {
+	return &Object{Keys: make([]string, 0), Values: make(map[string]any)}
+}
+
+// Set adds or replaces the value for k. New keys are added to the end.
+func (o *Object) Set(k string, v any) {
+	if _, ok := o.Values[k]; !ok {
+		o.Keys = append(o.Keys, k)
+	}
+	o.Values[k] = v
+}
+
+// Get returns the value for k and whether it is present.
+func (o *Object) Get(k string) (any, bool) {
+	v, ok := o.Values[k]
+	return v, ok
+}
+
+// Len returns the number of keys in the object.
+func (o *Object) Len() int {
+	return len(o.Keys)
+}
+
+// MarshalJSON encodes the object with its keys in order.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.Keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.Values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// decodeOrdered reads the next value from dec token by token, decoding objects
+// as an *Object so their key order is kept. The decoder should use numbers.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := NewObject()
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected object key at offset %d", dec.InputOffset())
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(k, v)
+		}
+		// consume the closing brace
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case json.Delim('['):
+		arr := make([]any, 0)
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return tok, nil
+}
+
+// objectEntries returns the keys and values of an object. The keys of an *Object
+// are in document order, while those of a map are sorted.
+func objectEntries(v any) ([]string, map[string]any, bool) {
+	switch v := v.(type) {
+	case *Object:
+		return v.Keys, v.Values, true
+	case map[string]any:
+		return sortedKeys(v), v, true
+	case JsonBlob:
+		return sortedKeys(v), v, true
+	}
+	return nil, nil, false
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// object creates an *Object from alternating keys and values.
+func object(kv ...any) *Object {
+	obj := NewObject()
+	for i := 0; i+1 < len(kv); i += 2 {
+		obj.Set(kv[i].(string), kv[i+1])
+	}
+	return obj
+}
+
+func TestDecodeOrdered(t *testing.T) {
+	content := `{"zeta": 1, "alpha": {"b": [true, null, "x"], "a": 2.5}, "mid": {}}`
+	dec := json.NewDecoder(bytes.NewReader([]byte(content)))
+	dec.UseNumber()
+	got, err := decodeOrdered(dec)
+	if err != nil {
+		t.Fatalf("decodeOrdered() error = %v", err)
+	}
+	want := object(
+		"zeta", json.Number("1"),
+		"alpha", object("b", []any{true, nil, "x"}, "a", json.Number("2.5")),
+		"mid", NewObject(),
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeOrdered() = %v, want %v", got, want)
+	}
+	out, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if want := `{"zeta":1,"alpha":{"b":[true,null,"x"],"a":2.5},"mid":{}}`; string(out) != want {
+		t.Errorf("json.Marshal() = %s, want %s", out, want)
+	}
+}
+
+func TestLoadJsonOrder(t *testing.T) {
+	doc, err := LoadJson([]byte(`{"zeta": 1, "alpha": {"y": 1, "x": 2}, "mid": 3}`))
+	if err != nil {
+		t.Fatalf("LoadJson() error = %v", err)
+	}
+	if got, want := nodeValues(doc.Nodes), []string{"zeta", "alpha", "mid"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadJson() keys = %v, want %v", got, want)
+	}
+	if got, want := nodeValues(doc.Nodes[1].Children), []string{"y", "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadJson() keys = %v, want %v", got, want)
+	}
+	if got := doc.Nodes[1].Desc; got != "1 2" {
+		t.Errorf("LoadJson() desc = %v, want 1 2", got)
+	}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// NewPickModel creates a model where enter quits, choosing the picked nodes of
+// the tree, or the selected node if none are picked. The choice is returned by
+// Picked once the program exits.
+func NewPickModel(tree *tree.Model) model {
+	m := NewModel(tree)
+	m.picking = true
+	tree.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{pickKeys.Choose}
+	}
+	return m
+}
+
+// pickKeys choose the nodes to print in pick mode.
+var pickKeys = struct {
+	Choose key.Binding
+}{
+	Choose: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "choose"),
+	),
+}
+
+// Picked returns the nodes chosen in a model created by NewPickModel, or false
+// if it quit without choosing any.
+func Picked(m tea.Model) ([]*tree.Node, bool) {
+	pm, ok := m.(model)
+	if !ok || pm.picked == nil {
+		return nil, false
+	}
+	return pm.picked, true
+}
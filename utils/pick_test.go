@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+func TestPickModel(t *testing.T) {
+	doc := mustLoad(t, `{"name": "api", "ports": [80, 443], "zone": "eu"}`)
+	tests := []struct {
+		name   string
+		multi  bool
+		keys   []tea.KeyMsg
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "enter chooses the selected node",
+			keys:   []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEnter}},
+			want:   []string{"$.ports"},
+			wantOK: true,
+		},
+		{
+			name:  "space picks several nodes",
+			multi: true,
+			keys: []tea.KeyMsg{
+				{Type: tea.KeySpace, Runes: []rune(" ")},
+				{Type: tea.KeyDown},
+				{Type: tea.KeyDown},
+				{Type: tea.KeySpace, Runes: []rune(" ")},
+				{Type: tea.KeyEnter},
+			},
+			want:   []string{"$.name", "$.ports[1]"},
+			wantOK: true,
+		},
+		{
+			name: "quitting chooses nothing",
+			keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("q")}},
+		},
+		{
+			name: "enter in a prompt does not choose",
+			keys: []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("/")}, {Type: tea.KeyEnter}, {Type: tea.KeyCtrlC}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			treeModel := doc.Treeify()
+			treeModel.SetMultiPick(tt.multi)
+			var m tea.Model = NewPickModel(treeModel)
+			for _, msg := range tt.keys {
+				m, _ = m.Update(msg)
+			}
+			nodes, ok := Picked(m)
+			if ok != tt.wantOK {
+				t.Fatalf("Picked() ok = %v, want %v", ok, tt.wantOK)
+			}
+			got := make([]string, 0)
+			for _, node := range nodes {
+				got = append(got, node.FormatPath(tree.PathJSONPath))
+			}
+			if tt.wantOK && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Picked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,842 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// Query is a compiled jq style expression which may be evaluated against decoded JSON.
+// Supported syntax:
+//
+//	.                 identity
+//	.a.b  ."a b"      object fields, with * matching any run of characters (.addr*, .*)
+//	.[2]  .[-1]       array indices, counting back from the end when negative
+//	.[1:3]            array slices
+//	.[]   .[*]        every element of an array or value of an object
+//	..                the value and all of its descendants
+//	f?                discard errors from f
+//	a | b             pipe the results of a into b
+//	select(f)         keep values for which f is truthy
+//	== != < <= > >=   comparisons, combined with and, or and not
+//	keys, length      builtin functions
+type Query struct {
+	source string
+	eval   queryFunc
+}
+
+type queryFunc func(v any) ([]any, error)
+
+// ParseQuery compiles source into a Query. An empty source is the identity.
+func ParseQuery(source string) (*Query, error) {
+	if strings.TrimSpace(source) == "" {
+		return &Query{source: source, eval: identity}, nil
+	}
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{tokens: tokens}
+	eval, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return &Query{source: source, eval: eval}, nil
+}
+
+// Eval runs the query against v, returning every result.
+func (q *Query) Eval(v any) ([]any, error) {
+	return q.eval(v)
+}
+
+func (q *Query) String() string {
+	return q.source
+}
+
+// Query evaluates the jq style expression source against the blob.
+func (d JsonBlob) Query(source string) ([]any, error) {
+	q, err := ParseQuery(source)
+	if err != nil {
+		return nil, err
+	}
+	return q.Eval(map[string]any(d))
+}
+
+// QueryNodes evaluates source against the blob and converts the results to tree nodes.
+func (d JsonBlob) QueryNodes(source string) ([]*tree.Node, error) {
+	results, err := d.Query(source)
+	if err != nil {
+		return nil, err
+	}
+	return treeifyResults(results), nil
+}
+
+// treeifyResults converts query results into top level nodes. A single object or
+// array is unwrapped so its children become the roots.
+func treeifyResults(results []any) []*tree.Node {
+	if len(results) == 1 {
+		entry := getTypedEntry(results[0])
+		if entry.Type == entryTypeMap || entry.Type == entryTypeArray {
+			nodes := entry.Treeify().Children
+			for _, node := range nodes {
+				node.Expand = true
+			}
+			return nodes
+		}
+	}
+	nodes := make([]*tree.Node, 0, len(results))
+	for i, result := range results {
+		node := getTypedEntry(result).Treeify()
+		node.Value = strconv.Itoa(i)
+		node.Expand = true
+		node.Synthetic = true
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenDot
+	tokenRecurse
+	tokenLBracket
+	tokenRBracket
+	tokenLParen
+	tokenRParen
+	tokenPipe
+	tokenColon
+	tokenQuestion
+	tokenOp
+	tokenIdent
+	tokenString
+	tokenNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits source into tokens.
+func lex(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRuneInString(source[i:])
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			continue
+		case strings.HasPrefix(source[i:], ".."):
+			tokens = append(tokens, token{kind: tokenRecurse, text: "..", pos: start})
+			i += 2
+		case r == '.' && (i+1 >= len(source) || !isDigit(source[i+1])):
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: start})
+			i++
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: start})
+			i++
+		case r == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: start})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: start})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: start})
+			i++
+		case r == '|':
+			tokens = append(tokens, token{kind: tokenPipe, text: "|", pos: start})
+			i++
+		case r == ':':
+			tokens = append(tokens, token{kind: tokenColon, text: ":", pos: start})
+			i++
+		case r == '?':
+			tokens = append(tokens, token{kind: tokenQuestion, text: "?", pos: start})
+			i++
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(source) && source[i] == '=' {
+				i++
+			}
+			op := source[start:i]
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unexpected %q at position %d", op, start)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: start})
+		case r == '"':
+			i++
+			for i < len(source) && source[i] != '"' {
+				if source[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(source) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			s, err := strconv.Unquote(source[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", start, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: start})
+		case isDigit(source[i]) || ((r == '-' || r == '.') && i+1 < len(source) && isDigit(source[i+1])):
+			i++
+			for i < len(source) && (isDigit(source[i]) || strings.ContainsRune(".eE", rune(source[i])) ||
+				(strings.ContainsRune("+-", rune(source[i])) && strings.ContainsRune("eE", rune(source[i-1])))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: source[start:i], pos: start})
+		case isIdentRune(r):
+			for i < len(source) {
+				r, size = utf8.DecodeRuneInString(source[i:])
+				if !isIdentRune(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i], pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, start)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '$' || r == '*' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if tok := p.next(); tok.kind != kind {
+		if tok.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of query", text)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", text, tok.pos, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) isKeyword(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && tok.text == text
+}
+
+// parsePipe parses `a | b | ...`.
+func (p *parser) parsePipe() (queryFunc, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenPipe {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		left = chain(left, right)
+	}
+	return left, nil
+}
+
+// parseOr parses `a or b or ...`.
+func (p *parser) parseOr() (queryFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical(left, right, true)
+	}
+	return left, nil
+}
+
+// parseAnd parses `a and b and ...`.
+func (p *parser) parseAnd() (queryFunc, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = logical(left, right, false)
+	}
+	return left, nil
+}
+
+// parseCompare parses `a op b`.
+func (p *parser) parseCompare() (queryFunc, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenOp {
+		return left, nil
+	}
+	op := p.next().text
+	right, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	return comparison(op, left, right), nil
+}
+
+// parsePostfix parses a term followed by any number of field accesses, indices and iterations.
+func (p *parser) parsePostfix() (queryFunc, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var suffix queryFunc
+		switch tok := p.peek(); tok.kind {
+		case tokenDot:
+			p.next()
+			suffix, err = p.parseAccess(false)
+		case tokenLBracket:
+			suffix, err = p.parseBracket()
+		case tokenRecurse:
+			p.next()
+			suffix = recurse
+		case tokenQuestion:
+			p.next()
+			term = optional(term)
+			continue
+		default:
+			return term, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		term = chain(term, suffix)
+	}
+}
+
+// parseTerm parses a path, literal, parenthesised expression or function call.
+func (p *parser) parseTerm() (queryFunc, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenDot:
+		return p.parseAccess(true)
+	case tokenRecurse:
+		return recurse, nil
+	case tokenLParen:
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(tokenRParen, ")")
+	case tokenString:
+		return literal(tok.text), nil
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return literal(n), nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return literal(true), nil
+		case "false":
+			return literal(false), nil
+		case "null":
+			return literal(nil), nil
+		case "not":
+			return not, nil
+		case "keys":
+			return keys, nil
+		case "length":
+			return length, nil
+		case "select":
+			if err := p.expect(tokenLParen, "("); err != nil {
+				return nil, err
+			}
+			cond, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokenRParen, ")"); err != nil {
+				return nil, err
+			}
+			return selectWhere(cond), nil
+		}
+		return nil, fmt.Errorf("unknown function %q at position %d", tok.text, tok.pos)
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// parseAccess parses what follows a dot. A bare dot is the identity when allowed.
+func (p *parser) parseAccess(allowIdentity bool) (queryFunc, error) {
+	switch tok := p.peek(); tok.kind {
+	case tokenIdent, tokenString:
+		p.next()
+		return field(tok.text), nil
+	case tokenLBracket:
+		return p.parseBracket()
+	default:
+		if allowIdentity {
+			return identity, nil
+		}
+		return nil, fmt.Errorf("expected field name at position %d", tok.pos)
+	}
+}
+
+// parseBracket parses `[]`, `[*]`, `["key"]`, `[n]` and `[n:m]`.
+func (p *parser) parseBracket() (queryFunc, error) {
+	if err := p.expect(tokenLBracket, "["); err != nil {
+		return nil, err
+	}
+	var op queryFunc
+	switch tok := p.peek(); {
+	case tok.kind == tokenRBracket:
+		op = iterate
+	case tok.kind == tokenIdent && tok.text == "*":
+		p.next()
+		op = iterate
+	case tok.kind == tokenString:
+		p.next()
+		op = field(tok.text)
+	case tok.kind == tokenNumber || tok.kind == tokenColon:
+		from, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenColon {
+			if from == nil {
+				return nil, fmt.Errorf("expected index at position %d", tok.pos)
+			}
+			op = index(*from)
+			break
+		}
+		p.next()
+		to, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		op = slice(from, to)
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return op, p.expect(tokenRBracket, "]")
+}
+
+// parseInt parses an optional integer.
+func (p *parser) parseInt() (*int, error) {
+	tok := p.peek()
+	if tok.kind != tokenNumber {
+		return nil, nil
+	}
+	p.next()
+	n, err := strconv.Atoi(tok.text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid index %q at position %d", tok.text, tok.pos)
+	}
+	return &n, nil
+}
+
+func identity(v any) ([]any, error) {
+	return []any{v}, nil
+}
+
+func literal(lit any) queryFunc {
+	return func(any) ([]any, error) {
+		return []any{lit}, nil
+	}
+}
+
+// chain feeds every result of first into second.
+func chain(first, second queryFunc) queryFunc {
+	return func(v any) ([]any, error) {
+		inputs, err := first(v)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]any, 0, len(inputs))
+		for _, input := range inputs {
+			outputs, err := second(input)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, outputs...)
+		}
+		return results, nil
+	}
+}
+
+// field looks up name in an object, treating * as a wildcard.
+func field(name string) queryFunc {
+	glob := strings.Contains(name, "*")
+	return func(v any) ([]any, error) {
+		switch v := v.(type) {
+		case nil:
+			if glob {
+				return nil, nil
+			}
+			return []any{nil}, nil
+		case []any:
+			if name == "*" {
+				return iterate(v)
+			}
+		}
+		if keys, values, ok := objectEntries(v); ok {
+			if !glob {
+				return []any{values[name]}, nil
+			}
+			results := make([]any, 0)
+			for _, k := range keys {
+				if matchGlob(name, k) {
+					results = append(results, values[k])
+				}
+			}
+			return results, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", typeName(v), name)
+	}
+}
+
+// matchGlob returns true if s matches pattern, where * matches any run of
+// characters. Unlike path.Match, / is not treated as a separator, as keys such
+// as URLs and annotations often contain it.
+func matchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	if len(parts) == 1 {
+		return s == ""
+	}
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+func index(i int) queryFunc {
+	return func(v any) ([]any, error) {
+		switch v := v.(type) {
+		case nil:
+			return []any{nil}, nil
+		case []any:
+			idx := i
+			if idx < 0 {
+				idx += len(v)
+			}
+			if idx < 0 || idx >= len(v) {
+				return []any{nil}, nil
+			}
+			return []any{v[idx]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with number", typeName(v))
+	}
+}
+
+func slice(from, to *int) queryFunc {
+	return func(v any) ([]any, error) {
+		switch v := v.(type) {
+		case nil:
+			return []any{nil}, nil
+		case []any:
+			start, end := 0, len(v)
+			if from != nil {
+				start = clampIndex(*from, len(v))
+			}
+			if to != nil {
+				end = clampIndex(*to, len(v))
+			}
+			if end < start {
+				end = start
+			}
+			return []any{v[start:end]}, nil
+		}
+		return nil, fmt.Errorf("cannot slice %s", typeName(v))
+	}
+}
+
+// clampIndex resolves negative indices and clamps i to [0, length].
+func clampIndex(i int, length int) int {
+	if i < 0 {
+		i += length
+	}
+	return max(0, min(i, length))
+}
+
+func iterate(v any) ([]any, error) {
+	switch v := v.(type) {
+	case []any:
+		return append([]any(nil), v...), nil
+	}
+	if keys, values, ok := objectEntries(v); ok {
+		results := make([]any, 0, len(keys))
+		for _, k := range keys {
+			results = append(results, values[k])
+		}
+		return results, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+}
+
+// recurse returns v and all of its descendants, depth first.
+func recurse(v any) ([]any, error) {
+	results := []any{v}
+	if children, err := iterate(v); err == nil {
+		for _, child := range children {
+			descendants, _ := recurse(child)
+			results = append(results, descendants...)
+		}
+	}
+	return results, nil
+}
+
+// optional discards any error from f.
+func optional(f queryFunc) queryFunc {
+	return func(v any) ([]any, error) {
+		results, err := f(v)
+		if err != nil {
+			return nil, nil
+		}
+		return results, nil
+	}
+}
+
+func selectWhere(cond queryFunc) queryFunc {
+	return func(v any) ([]any, error) {
+		outputs, err := cond(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, output := range outputs {
+			if truthy(output) {
+				return []any{v}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func not(v any) ([]any, error) {
+	return []any{!truthy(v)}, nil
+}
+
+func keys(v any) ([]any, error) {
+	switch v := v.(type) {
+	case []any:
+		results := make([]any, len(v))
+		for i := range v {
+			results[i] = float64(i)
+		}
+		return []any{results}, nil
+	}
+	if keys, _, ok := objectEntries(v); ok {
+		// like jq, keys are sorted regardless of document order
+		sorted := append([]string(nil), keys...)
+		sort.Strings(sorted)
+		results := make([]any, 0, len(sorted))
+		for _, k := range sorted {
+			results = append(results, k)
+		}
+		return []any{results}, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", typeName(v))
+}
+
+func length(v any) ([]any, error) {
+	switch v := v.(type) {
+	case nil:
+		return []any{float64(0)}, nil
+	case string:
+		return []any{float64(utf8.RuneCountInString(v))}, nil
+	case []any:
+		return []any{float64(len(v))}, nil
+	}
+	if keys, _, ok := objectEntries(v); ok {
+		return []any{float64(len(keys))}, nil
+	}
+	if n, ok := toNumber(v); ok {
+		return []any{math.Abs(n)}, nil
+	}
+	return nil, fmt.Errorf("%s has no length", typeName(v))
+}
+
+// logical combines the truthiness of every pair of results with and, or if or is set.
+func logical(left, right queryFunc, or bool) queryFunc {
+	return func(v any) ([]any, error) {
+		return product(v, left, right, func(a, b any) (any, error) {
+			if or {
+				return truthy(a) || truthy(b), nil
+			}
+			return truthy(a) && truthy(b), nil
+		})
+	}
+}
+
+func comparison(op string, left, right queryFunc) queryFunc {
+	return func(v any) ([]any, error) {
+		return product(v, left, right, func(a, b any) (any, error) {
+			return compare(op, a, b)
+		})
+	}
+}
+
+// product applies fn to every combination of the results of left and right.
+func product(v any, left, right queryFunc, fn func(a, b any) (any, error)) ([]any, error) {
+	lefts, err := left(v)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := right(v)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]any, 0, len(lefts)*len(rights))
+	for _, a := range lefts {
+		for _, b := range rights {
+			result, err := fn(a, b)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func compare(op string, a, b any) (bool, error) {
+	if op == "==" || op == "!=" {
+		return equal(a, b) == (op == "=="), nil
+	}
+	var cmp int
+	x, xok := toNumber(a)
+	y, yok := toNumber(b)
+	as, asok := a.(string)
+	bs, bsok := b.(string)
+	switch {
+	case xok && yok:
+		cmp = compareOrdered(x, y)
+	case asok && bsok:
+		cmp = strings.Compare(as, bs)
+	default:
+		return false, fmt.Errorf("cannot compare %s with %s", typeName(a), typeName(b))
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func compareOrdered(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func equal(a, b any) bool {
+	x, xok := toNumber(a)
+	y, yok := toNumber(b)
+	if xok && yok {
+		return x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func truthy(v any) bool {
+	return v != nil && v != false
+}
+
+func toNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// typeName returns the JSON name for the type of v.
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any, *Object:
+		return "object"
+	}
+	if _, ok := toNumber(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
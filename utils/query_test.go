@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	d := JsonBlob{
+		"ip": "1.1.1.1",
+		"location": map[string]any{
+			"city":         "Brisbane",
+			"country":      "Australia",
+			"country_code": "AU",
+			"coordinates":  map[string]any{"latitude": -27.48, "longitude": 153.01},
+		},
+		"ports": []any{80.0, 443.0, 8080.0},
+		"contacts": []any{
+			map[string]any{"type": "abuse", "email": "abuse@example.com"},
+			map[string]any{"type": "tech", "email": "tech@example.com"},
+		},
+	}
+	tests := []struct {
+		name  string
+		query string
+		want  []any
+	}{
+		{
+			name:  "empty",
+			query: "",
+			want:  []any{map[string]any(d)},
+		},
+		{
+			name:  "field",
+			query: ".location.city",
+			want:  []any{"Brisbane"},
+		},
+		{
+			name:  "quoted field",
+			query: `.location["country_code"]`,
+			want:  []any{"AU"},
+		},
+		{
+			name:  "missing field",
+			query: ".location.missing.deeper",
+			want:  []any{nil},
+		},
+		{
+			name:  "index",
+			query: ".ports[1]",
+			want:  []any{443.0},
+		},
+		{
+			name:  "negative index",
+			query: ".ports[-1]",
+			want:  []any{8080.0},
+		},
+		{
+			name:  "slice",
+			query: ".ports[:2]",
+			want:  []any{[]any{80.0, 443.0}},
+		},
+		{
+			name:  "iterate",
+			query: ".contacts[].email",
+			want:  []any{"abuse@example.com", "tech@example.com"},
+		},
+		{
+			name:  "wildcard field",
+			query: ".location.country*",
+			want:  []any{"Australia", "AU"},
+		},
+		{
+			name:  "wildcard",
+			query: ".location.coordinates.*",
+			want:  []any{-27.48, 153.01},
+		},
+		{
+			name:  "select",
+			query: `.contacts[] | select(.type == "tech") | .email`,
+			want:  []any{"tech@example.com"},
+		},
+		{
+			name:  "select and",
+			query: `.ports[] | select(. > 80 and . < 1000)`,
+			want:  []any{443.0},
+		},
+		{
+			name:  "recurse",
+			query: `.. | select(.latitude? != null) | .longitude`,
+			want:  []any{153.01},
+		},
+		{
+			name:  "keys",
+			query: ".location.coordinates | keys",
+			want:  []any{[]any{"latitude", "longitude"}},
+		},
+		{
+			name:  "length",
+			query: ".contacts | length",
+			want:  []any{2.0},
+		},
+		{
+			name:  "not",
+			query: ".ip | not",
+			want:  []any{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.Query(tt.query)
+			if err != nil {
+				t.Fatalf("JsonBlob.Query() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JsonBlob.Query() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	d := JsonBlob{"ports": []any{80.0}}
+	for _, query := range []string{
+		".ports.name",
+		".ports[",
+		".ports | frobnicate",
+		`.ports[] | select(. == "80"`,
+		".ports[0] | keys",
+		".ports[] < true",
+	} {
+		if _, err := d.Query(query); err == nil {
+			t.Errorf("JsonBlob.Query(%q) expected error", query)
+		}
+	}
+}
+
+func TestQuerySlashKeys(t *testing.T) {
+	d := JsonBlob{"a/b": 1.0, "c": 2.0}
+	got, err := d.Query(".*")
+	if err != nil {
+		t.Fatalf("JsonBlob.Query() error = %v", err)
+	}
+	if want := []any{1.0, 2.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("JsonBlob.Query() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"*", "kubernetes.io/name", true},
+		{"kubernetes.io/*", "kubernetes.io/name", true},
+		{"*/name", "kubernetes.io/name", true},
+		{"k*io*e", "kubernetes.io/name", true},
+		{"country*", "city", false},
+		{"a*a", "a", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "acb", false},
+		{"[a]*", "[a]b", true},
+		{"name", "name", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+// followInterval is how often a followed input is checked for new lines
+var followInterval = 250 * time.Millisecond
+
+// maxStreamBatch is the most lines delivered in a single NDJSONMsg
+const maxStreamBatch = 1000
+
+// NDJSONMsg carries lines read by an NDJSONStream.
+type NDJSONMsg struct {
+	Values []any
+	Nodes  []*tree.Node
+	// Err is the last error reading or parsing a line
+	Err error
+	// Done is true once the stream has finished and no more messages will be sent
+	Done bool
+}
+
+type streamLine struct {
+	value any
+	node  *tree.Node
+	err   error
+}
+
+// NDJSONStream parses NDJSON lines in the background. When following, it keeps
+// polling the input for lines appended after the end was reached, like tail -f.
+type NDJSONStream struct {
+	reader *NDJSONReader
+	follow bool
+	lines  chan streamLine
+}
+
+// NewNDJSONStream starts reading lines from r.
+func NewNDJSONStream(r io.Reader, label string, follow bool) *NDJSONStream {
+	s := &NDJSONStream{
+		reader: NewNDJSONReader(r, label),
+		follow: follow,
+		lines:  make(chan streamLine, maxStreamBatch),
+	}
+	go s.read()
+	return s
+}
+
+func (s *NDJSONStream) read() {
+	defer close(s.lines)
+	for {
+		value, node, err := s.reader.Next()
+		if errors.Is(err, io.EOF) {
+			if s.follow {
+				time.Sleep(followInterval)
+				continue
+			}
+			value, node, err = s.reader.Flush()
+			if node != nil || err != nil {
+				s.lines <- streamLine{value: value, node: node, err: err}
+			}
+			return
+		}
+		s.lines <- streamLine{value: value, node: node, err: err}
+		// a bad line is reported and skipped, but a failed read ends the stream
+		var lineErr *LineError
+		if err != nil && !errors.As(err, &lineErr) {
+			return
+		}
+	}
+}
+
+// Next returns a command waiting for the next lines from the stream.
+func (s *NDJSONStream) Next() tea.Cmd {
+	return func() tea.Msg {
+		line, ok := <-s.lines
+		if !ok {
+			return NDJSONMsg{Done: true}
+		}
+		msg := NDJSONMsg{}
+		for {
+			if line.err != nil {
+				msg.Err = line.err
+			} else {
+				msg.Values = append(msg.Values, line.value)
+				msg.Nodes = append(msg.Nodes, line.node)
+			}
+			if len(msg.Nodes) >= maxStreamBatch {
+				return msg
+			}
+			select {
+			case line, ok = <-s.lines:
+				if !ok {
+					msg.Done = true
+					return msg
+				}
+			default:
+				return msg
+			}
+		}
+	}
+}
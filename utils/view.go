@@ -3,38 +3,161 @@ package utils
 // Taken from https://github.com/savannahostrowski/tree-bubble/blob/main/example/main.go
 
 import (
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/crosleyzack/bubbles/tree"
 )
 
 var (
-	styleDoc = lipgloss.NewStyle().Padding(1)
+	styleDoc    = lipgloss.NewStyle().Padding(1)
+	styleStatus = lipgloss.NewStyle().Foreground(lipgloss.Color("#6272a4"))
+	styleError  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555"))
 )
 
 // NewModel creates a new model with the given tree.
 func NewModel(tree *tree.Model) model {
-	// set top level nodes to expanded
-	for _, node := range tree.Nodes() {
-		node.Expand = true
-	}
+	// show the children of the top level nodes
+	tree.ExpandToDepth(1)
+	return model{tree: tree}
+}
+
+// NewDiffModel creates a model showing a tree built by DiffDocuments, keeping
+// the nodes leading to each change expanded.
+func NewDiffModel(tree *tree.Model) model {
 	return model{tree: tree}
 }
 
+// NewStreamModel creates a model which appends the lines read by stream to doc
+// and the tree displaying it.
+func NewStreamModel(doc *Document, tree *tree.Model, stream *NDJSONStream) model {
+	m := NewModel(tree)
+	m.doc = doc
+	m.stream = stream
+	return m
+}
+
+// NewWatchModel creates a model which reloads doc and the tree displaying it
+// each time watcher sees its input change.
+func NewWatchModel(doc *Document, tree *tree.Model, watcher Watcher) model {
+	m := NewModel(tree)
+	m.doc = doc
+	m.watcher = watcher
+	return m
+}
+
+// NewCommandModel creates a model which shows the output of the command run by
+// watcher in doc and the tree displaying it, with a status line for the command
+// and a history of its recent outputs.
+func NewCommandModel(doc *Document, tree *tree.Model, watcher *CommandWatcher) model {
+	m := NewWatchModel(doc, tree, watcher)
+	m.command = watcher
+	m.history = &history{}
+	tree.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{historyKeys.Older, historyKeys.Newer}
+	}
+	return m
+}
+
 type model struct {
 	tree *tree.Model
+	// width is the width inside the padding, used to fit the status line
+	width int
+
+	doc     *Document
+	stream  *NDJSONStream
+	watcher Watcher
+
+	// command is the watcher of a command, whose status is shown above the tree
+	command *CommandWatcher
+	history *history
+	// lastRun is when the command last succeeded, and err why it failed since
+	lastRun time.Time
+	err     error
+
+	// picking is set in pick mode, where picked holds the nodes chosen on exit
+	picking bool
+	picked  []*tree.Node
 }
 
 func (m model) Init() tea.Cmd {
+	switch {
+	case m.stream != nil:
+		return m.stream.Next()
+	case m.watcher != nil:
+		return m.watcher.Next()
+	}
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// the tree is drawn inside the padding of the document style, below the status
+		top, right, bottom, left := styleDoc.GetPadding()
+		m.width = msg.Width - left - right
+		m.tree.SetSize(m.width, msg.Height-top-bottom-m.statusHeight())
+		return m, nil
+	case tea.MouseMsg:
+		// make the position relative to the tree inside the padding
+		top, _, _, left := styleDoc.GetPadding()
+		msg.X -= left
+		msg.Y -= top + m.statusHeight()
+		var cmd tea.Cmd
+		m.tree, cmd = m.tree.Update(msg)
+		return m, cmd
+	case NDJSONMsg:
+		for _, node := range msg.Nodes {
+			node.Expand = true
+		}
+		m.doc.Values = append(m.doc.Values, msg.Values...)
+		m.doc.Nodes = append(m.doc.Nodes, msg.Nodes...)
+		// query results are not updated, the new lines are shown once the query is cleared
+		if m.tree.QueryString() == "" {
+			m.tree.AppendNodes(msg.Nodes...)
+		}
+		if msg.Err != nil {
+			m.tree.SetStatus(msg.Err.Error())
+		}
+		if msg.Done {
+			return m, nil
+		}
+		return m, m.stream.Next()
+	case ReloadMsg:
+		return m, tea.Batch(m.reload(msg), m.watcher.Next())
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "q", "ctrl+c":
+		case "ctrl+c":
 			return m, tea.Quit
+		case "q":
+			// allow q to be typed into the search and filter prompts
+			if !m.tree.Prompting() {
+				return m, tea.Quit
+			}
+		}
+		if m.picking && !m.tree.Prompting() && key.Matches(msg, pickKeys.Choose) {
+			m.picked = m.tree.Picked()
+			if len(m.picked) == 0 {
+				if node := m.tree.SelectedNode(); node != nil {
+					m.picked = []*tree.Node{node}
+				}
+			}
+			if m.picked == nil {
+				return m, nil
+			}
+			return m, tea.Quit
+		}
+		if m.history != nil && !m.tree.Prompting() {
+			switch {
+			case key.Matches(msg, historyKeys.Older):
+				return m, m.step(-1)
+			case key.Matches(msg, historyKeys.Newer):
+				return m, m.step(1)
+			}
 		}
 	}
 	var cmd tea.Cmd
@@ -42,6 +165,85 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// reload shows a document which was read again. Failures leave the previous
+// document shown, with the error in the status.
+func (m *model) reload(msg ReloadMsg) tea.Cmd {
+	if m.history == nil {
+		if msg.Err != nil {
+			m.tree.SetStatus("reload failed: " + msg.Err.Error())
+			return nil
+		}
+		m.tree.SetStatus("reloaded at " + time.Now().Format(time.TimeOnly))
+		return m.show(msg.Doc)
+	}
+	m.err = msg.Err
+	if msg.Err != nil {
+		return nil
+	}
+	m.lastRun = time.Now()
+	if m.history.add(msg.Content, m.lastRun) {
+		return m.show(msg.Doc)
+	}
+	return nil
+}
+
+// step shows the output of the command delta runs after the one shown.
+func (m *model) step(delta int) tea.Cmd {
+	entry, ok := m.history.step(delta)
+	if !ok {
+		return nil
+	}
+	doc, err := Load(entry.content, m.command.format)
+	if err != nil {
+		m.tree.SetStatus(err.Error())
+		return nil
+	}
+	return m.show(doc)
+}
+
+// show replaces the document, rerunning any active query on the new one.
+func (m *model) show(doc *Document) tea.Cmd {
+	// the query function refers to the document, so update it in place
+	*m.doc = *doc
+	nodes, err := m.doc.QueryNodes(m.tree.QueryString())
+	if err != nil {
+		m.tree.SetStatus("query failed: " + err.Error())
+		return nil
+	}
+	return m.tree.ReloadNodes(nodes)
+}
+
+// statusHeight returns the number of lines used by the status of a watched command.
+func (m model) statusHeight() int {
+	if m.command == nil {
+		return 0
+	}
+	return 1
+}
+
+// statusView describes the command being watched, when it last ran and whether
+// it failed, or which of its previous outputs is shown.
+func (m model) statusView() string {
+	status := styleStatus.Render("every " + m.command.Interval().String() + ": " + m.command.Command())
+	switch {
+	case !m.history.latest():
+		entry := m.history.entries[m.history.current]
+		status += styleStatus.Render("  history " + strconv.Itoa(m.history.current+1) + "/" + strconv.Itoa(len(m.history.entries)) +
+			" at " + entry.time.Format(time.TimeOnly) + ", paused")
+	case !m.lastRun.IsZero():
+		status += styleStatus.Render("  at " + m.lastRun.Format(time.TimeOnly))
+	default:
+		status += styleStatus.Render("  running")
+	}
+	if m.err != nil {
+		status += styleError.Render("  " + m.err.Error())
+	}
+	return ansi.Truncate(status, m.width, "…")
+}
+
 func (m model) View() string {
+	if m.command != nil {
+		return styleDoc.Render(lipgloss.JoinVertical(lipgloss.Left, m.statusView(), m.tree.View()))
+	}
 	return styleDoc.Render(m.tree.View())
 }
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchInterval is how often a watched file is checked for changes
+var watchInterval = 500 * time.Millisecond
+
+// ReloadMsg carries a document re-read after its file changed, or the output of
+// a command run again.
+type ReloadMsg struct {
+	Doc *Document
+	// Content is the input the document was parsed from
+	Content []byte
+	// Err is set if the input could not be read or parsed, in which case Doc is nil
+	Err error
+}
+
+// Watcher produces a ReloadMsg each time its input changes.
+type Watcher interface {
+	Next() tea.Cmd
+}
+
+// FileWatcher polls a file for changes, parsing it again each time it is written.
+type FileWatcher struct {
+	path   string
+	format Format
+
+	modTime time.Time
+	size    int64
+	// missing is set while the file cannot be read, so the error is only reported once
+	missing bool
+}
+
+// NewFileWatcher starts watching the file at path, which is parsed in the given
+// format or detected from its content if the format is empty.
+func NewFileWatcher(path string, format Format) (*FileWatcher, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileWatcher{path: path, format: format, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// Next returns a command which waits for the file to change and then sends a
+// ReloadMsg with its new content.
+func (w *FileWatcher) Next() tea.Cmd {
+	return func() tea.Msg {
+		for {
+			time.Sleep(watchInterval)
+			info, err := os.Stat(w.path)
+			if err != nil {
+				// editors may replace the file rather than writing it, so keep watching
+				if w.missing {
+					continue
+				}
+				w.missing = true
+				return ReloadMsg{Err: err}
+			}
+			if !w.missing && info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+				continue
+			}
+			w.missing = false
+			w.modTime, w.size = info.ModTime(), info.Size()
+			return w.load()
+		}
+	}
+}
+
+// load reads and parses the file.
+func (w *FileWatcher) load() ReloadMsg {
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		return ReloadMsg{Err: err}
+	}
+	doc, err := Load(content, w.format)
+	if err != nil {
+		return ReloadMsg{Err: fmt.Errorf("parsing %s: %w", w.path, err)}
+	}
+	return ReloadMsg{Doc: doc, Content: content}
+}
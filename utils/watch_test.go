@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileWatcher(t *testing.T) {
+	defer func(interval time.Duration) { watchInterval = interval }(watchInterval)
+	watchInterval = time.Millisecond
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := os.WriteFile(path, []byte(`{"a": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewFileWatcher(path, "")
+	if err != nil {
+		t.Fatalf("NewFileWatcher() error = %v", err)
+	}
+	next := w.Next()
+
+	if err := os.WriteFile(path, []byte(`{"a": 1, "b": 2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	msg := next().(ReloadMsg)
+	if msg.Err != nil {
+		t.Fatalf("ReloadMsg error = %v", msg.Err)
+	}
+	if got, want := nodeValues(msg.Doc.Nodes), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReloadMsg nodes = %v, want %v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"a": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if msg := w.Next()().(ReloadMsg); msg.Err == nil {
+		t.Errorf("ReloadMsg error = nil, want parse error")
+	}
+
+	// a missing file is reported once, then reloaded when it is back
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if msg := w.Next()().(ReloadMsg); !os.IsNotExist(msg.Err) {
+		t.Errorf("ReloadMsg error = %v, want not exist", msg.Err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		// rename into place, so the file is never seen empty
+		os.WriteFile(path+".tmp", []byte(`[1]`), 0o644)
+		os.Rename(path+".tmp", path)
+	}()
+	if msg := w.Next()().(ReloadMsg); msg.Err != nil || len(msg.Doc.Nodes) != 1 {
+		t.Errorf("ReloadMsg = %v, want one node", msg)
+	}
+
+	if _, err := NewFileWatcher(filepath.Join(t.TempDir(), "missing.json"), ""); err == nil {
+		t.Errorf("NewFileWatcher() error = nil, want not exist")
+	}
+}
@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/crosleyzack/bubbles/tree"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadYaml parses a stream of one or more YAML documents. A single document is
+// displayed like JSON, while a stream has one top level node per document.
+func LoadYaml(content []byte) (*Document, error) {
+	docs := make([]*yaml.Node, 0)
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+	d := &Document{
+		Values: make([]any, 0, len(docs)),
+		Nodes:  make([]*tree.Node, 0, len(docs)),
+	}
+	for i, doc := range docs {
+		value := yamlValue(doc, make(map[*yaml.Node]bool))
+		d.Values = append(d.Values, value)
+		node := treeifyYaml(doc, value, make(map[*yaml.Node]bool))
+		node.Value = strconv.Itoa(i)
+		node.InArray = true
+		node.Expand = true
+		node.Synthetic = true
+		d.Nodes = append(d.Nodes, node)
+	}
+	if len(docs) == 1 && len(d.Nodes[0].Children) > 0 {
+		d.Nodes = d.Nodes[0].Children
+		for _, node := range d.Nodes {
+			node.Expand = true
+		}
+	}
+	return d, nil
+}
+
+// yamlEntry is a key of a mapping and the node holding its value.
+type yamlEntry struct {
+	key   string
+	value *yaml.Node
+}
+
+// mappingEntries returns the entries of a mapping in document order, with the
+// entries of mappings merged with << taking the place of the merge key unless
+// they are set explicitly. Merges of a mapping in expanding, which contains n,
+// are skipped as they would never end.
+func mappingEntries(n *yaml.Node, expanding map[*yaml.Node]bool) []yamlEntry {
+	explicit := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if key := n.Content[i]; key.ShortTag() != "!!merge" {
+			explicit[key.Value] = true
+		}
+	}
+	entries := make([]yamlEntry, 0, len(n.Content)/2)
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			if !seen[key.Value] {
+				seen[key.Value] = true
+				entries = append(entries, yamlEntry{key: key.Value, value: value})
+			}
+			continue
+		}
+		merged := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			merged = value.Content
+		}
+		for _, item := range merged {
+			for item.Kind == yaml.AliasNode {
+				item = item.Alias
+			}
+			if item.Kind != yaml.MappingNode || expanding[item] {
+				continue
+			}
+			expanding[item] = true
+			for _, entry := range mappingEntries(item, expanding) {
+				if !seen[entry.key] && !explicit[entry.key] {
+					seen[entry.key] = true
+					entries = append(entries, entry)
+				}
+			}
+			delete(expanding, item)
+		}
+	}
+	return entries
+}
+
+// yamlValue converts a YAML node into the values produced by LoadJson,
+// resolving aliases and merge keys and formatting any other scalar as a string.
+// expanding holds the sequences and mappings containing n, and an alias of one
+// of them, which would expand forever, is converted to its name instead.
+func yamlValue(n *yaml.Node, expanding map[*yaml.Node]bool) any {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return yamlValue(n.Content[0], expanding)
+	case yaml.AliasNode:
+		if expanding[n.Alias] {
+			return "*" + n.Value
+		}
+		return yamlValue(n.Alias, expanding)
+	case yaml.SequenceNode:
+		expanding[n] = true
+		defer delete(expanding, n)
+		values := make([]any, 0, len(n.Content))
+		for _, item := range n.Content {
+			values = append(values, yamlValue(item, expanding))
+		}
+		return values
+	case yaml.MappingNode:
+		expanding[n] = true
+		defer delete(expanding, n)
+		obj := NewObject()
+		for _, entry := range mappingEntries(n, expanding) {
+			obj.Set(entry.key, yamlValue(entry.value, expanding))
+		}
+		return obj
+	}
+	switch n.ShortTag() {
+	case "!!null":
+		return nil
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err == nil {
+			return b
+		}
+	case "!!int":
+		var i int
+		if err := n.Decode(&i); err == nil {
+			return i
+		}
+		var f float64
+		if err := n.Decode(&f); err == nil {
+			return f
+		}
+	case "!!float":
+		var f float64
+		if err := n.Decode(&f); err == nil {
+			return f
+		}
+	}
+	return n.Value
+}
+
+// treeifyYaml converts a YAML node and its value from yamlValue into a tree
+// node, keeping mapping keys in the order of the value. Anchors and aliases are
+// noted in the description, with aliases showing the content they refer to,
+// unless they refer to a node in expanding which contains them.
+func treeifyYaml(n *yaml.Node, value any, expanding map[*yaml.Node]bool) *tree.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		return treeifyYaml(n.Content[0], value, expanding)
+	}
+	if n.Kind == yaml.AliasNode && expanding[n.Alias] {
+		return &tree.Node{
+			Desc:     "*" + n.Value + " (cycle)",
+			Children: make([]*tree.Node, 0),
+			Data:     value,
+			Type:     tree.TypeString,
+		}
+	}
+	entry := getTypedEntry(value)
+	node := &tree.Node{
+		Desc:     entry.String(),
+		Children: make([]*tree.Node, 0),
+		Data:     value,
+		Type:     entry.Type.valueType(),
+	}
+	target := n
+	switch {
+	case n.Kind == yaml.AliasNode:
+		node.Desc = "*" + n.Value + " " + node.Desc
+		target = n.Alias
+	case n.Anchor != "":
+		node.Desc = "&" + n.Anchor + " " + node.Desc
+	}
+	expanding[target] = true
+	defer delete(expanding, target)
+	switch target.Kind {
+	case yaml.SequenceNode:
+		values, _ := value.([]any)
+		for i, item := range target.Content {
+			var v any
+			if i < len(values) {
+				v = values[i]
+			}
+			child := treeifyYaml(item, v, expanding)
+			child.Value = strconv.Itoa(i)
+			child.InArray = true
+			node.Children = append(node.Children, child)
+		}
+	case yaml.MappingNode:
+		obj, ok := value.(*Object)
+		if !ok {
+			obj = NewObject()
+		}
+		for _, e := range mappingEntries(target, expanding) {
+			child := treeifyYaml(e.value, obj.Values[e.key], expanding)
+			child.Value = e.key
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node
+}
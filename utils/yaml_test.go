@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/crosleyzack/bubbles/tree"
+)
+
+func nodeValues(nodes []*tree.Node) []string {
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, node.Value)
+	}
+	return values
+}
+
+func TestLoadYaml(t *testing.T) {
+	content := `
+zeta: 1
+alpha: &base
+  image: nginx
+  replicas: 2
+web:
+  <<: *base
+  replicas: 3
+  ports: [80, 443]
+`
+	doc, err := LoadYaml([]byte(content))
+	if err != nil {
+		t.Fatalf("LoadYaml() error = %v", err)
+	}
+	if got, want := nodeValues(doc.Nodes), []string{"zeta", "alpha", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadYaml() keys = %v, want %v", got, want)
+	}
+	alpha, web := doc.Nodes[1], doc.Nodes[2]
+	if !strings.HasPrefix(alpha.Desc, "&base ") {
+		t.Errorf("anchor desc = %q, want &base prefix", alpha.Desc)
+	}
+	// merged keys are shown in place of the merge key, as they are in the value
+	if got, want := nodeValues(web.Children), []string{"image", "replicas", "ports"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadYaml() keys = %v, want %v", got, want)
+	}
+	doc.Treeify()
+	image := web.Children[0]
+	if got, err := doc.Query(image.FormatPath(tree.PathJq)); err != nil || !reflect.DeepEqual(got, []any{"nginx"}) {
+		t.Errorf("Document.Query(%s) = %v, %v, want nginx", image.FormatPath(tree.PathJq), got, err)
+	}
+	if replicas := web.Children[1]; replicas.Data != 3 {
+		t.Errorf("overridden merge key = %v, want 3", replicas.Data)
+	}
+	if ports := web.Children[2]; !ports.Children[1].InArray || ports.Children[1].Data != 443 {
+		t.Errorf("array item = %+v, want index with value 443", ports.Children[1])
+	}
+	wantWeb := object("image", "nginx", "replicas", 3, "ports", []any{80, 443})
+	if !reflect.DeepEqual(web.Data, wantWeb) {
+		t.Errorf("merged value = %v, want %v", web.Data, wantWeb)
+	}
+}
+
+func TestLoadYamlStream(t *testing.T) {
+	content := `
+kind: Deployment
+enabled: true
+---
+kind: Service
+ratio: 0.5
+---
+- a
+- ~
+`
+	doc, err := LoadYaml([]byte(content))
+	if err != nil {
+		t.Fatalf("LoadYaml() error = %v", err)
+	}
+	if got, want := nodeValues(doc.Nodes), []string{"0", "1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadYaml() documents = %v, want %v", got, want)
+	}
+	want := []any{
+		object("kind", "Deployment", "enabled", true),
+		object("kind", "Service", "ratio", 0.5),
+		[]any{"a", nil},
+	}
+	if !reflect.DeepEqual(doc.Values, want) {
+		t.Errorf("LoadYaml() values = %v, want %v", doc.Values, want)
+	}
+	kinds, err := doc.Query(".kind?")
+	if err != nil {
+		t.Fatalf("Document.Query() error = %v", err)
+	}
+	if want := []any{"Deployment", "Service"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Document.Query() = %v, want %v", kinds, want)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{
+		"data.json":   FormatJSON,
+		"values.yaml": FormatYAML,
+		"deploy.YML":  FormatYAML,
+		"logs.jsonl":  FormatNDJSON,
+		"noext":       "",
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestLoadYamlCyclicAlias(t *testing.T) {
+	doc, err := LoadYaml([]byte("a: &x\n  b: *x\n  c: 1\n"))
+	if err != nil {
+		t.Fatalf("LoadYaml() error = %v", err)
+	}
+	if want := object("b", "*x", "c", 1); !reflect.DeepEqual(doc.Values[0].(*Object).Values["a"], want) {
+		t.Errorf("LoadYaml() value = %v, want %v", doc.Values[0], want)
+	}
+	ref := doc.Nodes[0].Children[0]
+	if ref.Desc != "*x (cycle)" || len(ref.Children) != 0 {
+		t.Errorf("alias = %q with %d children, want an unexpanded reference", ref.Desc, len(ref.Children))
+	}
+}